@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	command "github.com/shuttlerock/changlog/pkg/cmd"
+)
+
+func NewCmdChangelogBackports() (*cobra.Command, *command.BackportsOptions) {
+	o := &command.BackportsOptions{}
+	cmd := &cobra.Command{
+		Use:   "backports",
+		Short: "Lists the fixes on the main branch which have not been backported to a release branch",
+		Run: func(cmd *cobra.Command, args []string) {
+			err := o.Run()
+			handleError(err)
+		},
+	}
+	return cmd, o
+}
+
+func init() {
+	backportsCmd, options := NewCmdChangelogBackports()
+	rootCmd.AddCommand(backportsCmd)
+	backportsCmd.Flags().StringVarP(&options.Branch, "branch", "b", "", "the release branch to check for missing backports")
+	backportsCmd.Flags().StringVarP(&options.MainBranch, "main", "", "main", "the main branch the fixes are made on")
+	backportsCmd.Flags().StringVarP(&options.Dir, GitDirFlag, "", ".", "the directory to search for the .git")
+}
@@ -3,7 +3,9 @@ package cmd
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/shuttlerock/changlog/pkg/changelog"
 	command "github.com/shuttlerock/changlog/pkg/cmd"
+	"github.com/shuttlerock/changlog/pkg/retry"
 )
 
 const (
@@ -33,4 +35,27 @@ func init() {
 	createCmd.Flags().StringVarP(&options.ReleaseYamlFile, ReleaseYamlFlag, "", "release.yaml", "the name of the file to generate the Release YAML")
 	createCmd.Flags().StringVarP(&options.GitDir, GitDirFlag, "", ".", "the directory to search for the .git to discover the git source URL")
 	createCmd.Flags().StringVarP(&options.OutputMarkdownFile, "output-markdown", "", "", "Put the changelog output in this file")
+	createCmd.Flags().StringVarP(&options.ConfigFile, "config", "", "", "the changelog configuration file. Defaults to .changelog.yaml in the git directory")
+	createCmd.Flags().BoolVarP(&options.AggregatePrereleases, "aggregate-prereleases", "", true, "use the previous semantic version as the previous release, skipping pre-releases for a final release so it includes the changes of all its release candidates")
+	createCmd.Flags().BoolVarP(&options.FirstParent, "first-parent", "", false, "only follow the first parent of commits so that each merge commit is one entry with the title of the pull request it merged")
+	createCmd.Flags().BoolVarP(&options.NestMergedCommits, "nest-merged-commits", "", false, "when using --first-parent list the commits of each merged branch under the merge commit")
+	createCmd.Flags().BoolVarP(&options.ShowExcluded, "show-excluded", "", false, "list the commits dropped from the changelog and the reason why")
+	createCmd.Flags().StringVarP(&options.GroupBy, "group-by", "", changelog.GroupByType, "how to group the changelog entries: 'type' for conventional commit types, 'labels' for pull request labels, 'team' for the teams of the commit authors or changed files or 'issue-type' for the types of the linked issues such as Bug")
+	createCmd.Flags().StringVarP(&options.View, "view", "", changelog.ViewCommits, "how to list the changelog entries: 'commits' for the commits or 'issues' for each issue and pull request once with its status and commits, grouped by epic which cannot be combined with --group-by")
+	createCmd.Flags().BoolVarP(&options.Umbrella, "umbrella", "", false, "include the changes of the chart dependencies whose version changed, using their repositories in the dependencies directory")
+	createCmd.Flags().StringVarP(&options.DependenciesDir, "dependencies-dir", "", "", "the directory containing a clone of each chart dependency repository named after the dependency. Defaults to the parent of the git directory")
+	createCmd.Flags().StringVarP(&options.Chart, "chart", "", "", "the name of the chart to create the Release for in a repository with more than one chart. Defaults to all of the charts")
+	createCmd.Flags().BoolVarP(&options.NoHelm, "no-helm", "", false, "write the Release YAML with the resolved name and without escaping for Helm as it is not rendered as a chart template")
+	createCmd.Flags().BoolVarP(&options.Apply, "apply", "", false, "create or update the Release resource in the kubernetes cluster of the current kubeconfig")
+	createCmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "the namespace to apply the Release to. Defaults to 'default'")
+	createCmd.Flags().BoolVarP(&options.ServerSideApply, "server-side", "", true, "use server-side apply when applying the Release, otherwise the existing Release is replaced")
+	createCmd.Flags().StringVarP(&options.IssueTracker, "issue-tracker", "", command.IssueTrackerJira, "the issue tracker to look up the issues referenced by the commits: 'jira' or 'file' for a local YAML or JSON file of issues")
+	createCmd.Flags().StringVarP(&options.IssuesFile, "issues-file", "", "", "the YAML or JSON file of issues with their key, title, state, labels and assignees used by --issue-tracker file")
+	createCmd.Flags().BoolVarP(&options.Offline, "offline", "", false, "do not call the git provider or issue tracker and mark the release as not enriched with their details")
+	createCmd.Flags().BoolVarP(&options.Strict, "strict", "", false, "fail before writing any files if any issue referenced by the commits of the charts or their dependencies is missing from the issue tracker. Unknown keys of projects other than the issueProjects of the configuration such as UTF-8 are ignored. It requires an issue tracker which is either the issues file or a Jira server when online")
+	createCmd.Flags().StringVarP(&options.DiagnosticsFile, "diagnostics-file", "", "", "write a JSON report of the problems looking up the issues, pull requests and users of the commits to this file")
+	createCmd.Flags().DurationVarP(&options.Timeout, "timeout", "", 0, "the maximum time to spend calling the git provider and issue tracker after which the remaining lookups are skipped. No limit if zero")
+	createCmd.Flags().DurationVarP(&options.CallTimeout, "call-timeout", "", retry.DefaultTimeout, "the timeout of each call to the git provider or issue tracker")
+	createCmd.Flags().IntVarP(&options.Retries, "retries", "", retry.DefaultMaxRetries, "the number of times a call to the git provider or issue tracker is retried with exponential backoff after a timeout, network error, rate limit or server error")
+	createCmd.Flags().BoolVarP(&options.Check, "check", "", false, "fail with a diff if the generated files are out of date instead of writing them")
 }
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	command "github.com/shuttlerock/changlog/pkg/cmd"
+)
+
+func NewCmdChangelogPrune() (*cobra.Command, *command.PruneOptions) {
+	o := &command.PruneOptions{}
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Deletes the old Release resources of each application",
+		Run: func(cmd *cobra.Command, args []string) {
+			err := o.Run()
+			handleError(err)
+		},
+	}
+	return cmd, o
+}
+
+func init() {
+	pruneCmd, options := NewCmdChangelogPrune()
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "the namespace of the Release resources. Defaults to 'default'")
+	pruneCmd.Flags().IntVarP(&options.Keep, "keep", "k", 0, "the number of the newest Release resources of each application to keep")
+	pruneCmd.Flags().DurationVarP(&options.OlderThan, "older-than", "", 0, "delete the Release resources older than this age such as 2160h. When used with --keep the newest ones are still kept")
+	pruneCmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "list the Release resources which would be deleted without deleting them")
+}
@@ -0,0 +1,31 @@
+package changelog
+
+import (
+	"encoding/json"
+
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+)
+
+// MaxAnnotationSize the size of the largest JSON annotation added to the Release so that all of its annotations stay
+// well within the 256KiB total allowed by Kubernetes
+const MaxAnnotationSize = 32 * 1024
+
+// setJSONAnnotation annotates the release with the JSON of the value. The annotation is left out if it would be
+// larger than MaxAnnotationSize as the value is still available in the Result
+func setJSONAnnotation(release *v1alpha1.Release, name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal annotation %s", name)
+	}
+	if len(data) > MaxAnnotationSize {
+		log.Logger().Warnf("not adding annotation %s to the Release as it is %d bytes which is more than %d", name, len(data), MaxAnnotationSize)
+		return nil
+	}
+	if release.Annotations == nil {
+		release.Annotations = map[string]string{}
+	}
+	release.Annotations[name] = string(data)
+	return nil
+}
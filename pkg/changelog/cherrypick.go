@@ -0,0 +1,152 @@
+package changelog
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/diff"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const (
+	// AnnotationPrefix the prefix of the annotations added to the Release. The Release has no fields for the
+	// cherry-picks, teams, issue details, contributors and dependency commits so they are JSON annotations of the
+	// Release YAML instead, each left out if larger than MaxAnnotationSize
+	AnnotationPrefix = "changelog.shuttlerock.com/"
+
+	// CherryPicksAnnotation the annotation of the JSON object mapping the SHA of each cherry-picked commit to the
+	// SHA of the commit it was cherry-picked from as the CommitSummary has no field for it
+	CherryPicksAnnotation = AnnotationPrefix + "cherry-picks"
+)
+
+// CherryPickRegex matches the trailer added by `git cherry-pick -x`
+var CherryPickRegex = regexp.MustCompile(`(?m)^\(cherry picked from commit ([0-9a-f]{7,40})\)\s*$`)
+
+// CherryPickedFrom returns the SHAs of the commits the commit message says it was cherry-picked from
+func CherryPickedFrom(message string) []string {
+	var answer []string
+	for _, match := range CherryPickRegex.FindAllStringSubmatch(message, -1) {
+		answer = append(answer, match[1])
+	}
+	return answer
+}
+
+// PatchID returns a fingerprint of the changes made by a commit which ignores whitespace, line numbers and the
+// context of the changes so that a cherry-picked commit has the same patch id as the original.
+// An empty string is returned for commits without a single parent or without any changes
+func PatchID(commit *object.Commit) (string, error) {
+	if commit.NumParents() != 1 {
+		return "", nil
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the parent of commit %s", commit.Hash.String())
+	}
+	patch, err := parent.Patch(commit)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create the patch of commit %s", commit.Hash.String())
+	}
+
+	h := sha1.New()
+	changed := false
+	for _, fp := range patch.FilePatches() {
+		from, to := fp.Files()
+		if from != nil {
+			h.Write([]byte("--- " + from.Path() + "\n"))
+		}
+		if to != nil {
+			h.Write([]byte("+++ " + to.Path() + "\n"))
+		}
+		for _, chunk := range fp.Chunks() {
+			prefix := ""
+			switch chunk.Type() {
+			case diff.Add:
+				prefix = "+"
+			case diff.Delete:
+				prefix = "-"
+			default:
+				continue
+			}
+			for _, line := range strings.Split(chunk.Content(), "\n") {
+				line = strings.Join(strings.Fields(line), "")
+				if line != "" {
+					h.Write([]byte(prefix + line + "\n"))
+					changed = true
+				}
+			}
+		}
+	}
+	if !changed {
+		return "", nil
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ShaMatches returns true if the possibly abbreviated SHA refers to the full SHA
+func ShaMatches(abbreviated, sha string) bool {
+	return abbreviated != "" && strings.HasPrefix(sha, abbreviated)
+}
+
+// findCherryPickDuplicates returns the commits which repeat the change of another commit in the list, either because
+// they were cherry-picked from it or have the same patch id, mapped to the SHA of the commit they duplicate.
+// The commits are in reverse chronological order so the oldest commit of each change is kept
+func findCherryPickDuplicates(commits []object.Commit) map[string]string {
+	answer := map[string]string{}
+	patchIDs := map[string]string{}
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := &commits[i]
+		if len(c.ParentHashes) > 1 {
+			continue
+		}
+		sha := c.Hash.String()
+
+		original := ""
+		for _, from := range CherryPickedFrom(c.Message) {
+			for k := range commits {
+				if ShaMatches(from, commits[k].Hash.String()) {
+					original = commits[k].Hash.String()
+					break
+				}
+			}
+		}
+		if original == "" {
+			patchID, err := PatchID(c)
+			if err != nil {
+				log.Logger().Debugf("failed to find the patch id of commit %s: %v", sha, err)
+			} else if patchID != "" {
+				original = patchIDs[patchID]
+				if original == "" {
+					patchIDs[patchID] = sha
+				}
+			}
+		}
+		if original != "" && original != sha {
+			answer[sha] = original
+		}
+	}
+	return answer
+}
+
+// addCherryPick records the original commit of a cherry-picked commit
+func (g *Generator) addCherryPick(commit *object.Commit) {
+	from := CherryPickedFrom(commit.Message)
+	if len(from) == 0 {
+		return
+	}
+	if g.result.CherryPicks == nil {
+		g.result.CherryPicks = map[string]string{}
+	}
+	g.result.CherryPicks[commit.Hash.String()] = from[len(from)-1]
+}
+
+// addCherryPicksAnnotation annotates the release with the original commits of its cherry-picked commits
+func (g *Generator) addCherryPicksAnnotation() error {
+	if len(g.result.CherryPicks) == 0 {
+		return nil
+	}
+	return setJSONAnnotation(g.result.Release, CherryPicksAnnotation, g.result.CherryPicks)
+}
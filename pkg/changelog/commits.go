@@ -0,0 +1,173 @@
+package changelog
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+)
+
+var (
+	groupCounter = 0
+	// ConventionalCommitTitles textual descriptions for
+	// Conventional Commit types: https://conventionalcommits.org/
+	ConventionalCommitTitles = map[string]*CommitGroup{
+		"feat":     createCommitGroup("New Features"),
+		"fix":      createCommitGroup("Bug Fixes"),
+		"perf":     createCommitGroup("Performance Improvements"),
+		"refactor": createCommitGroup("Code Refactoring"),
+		"docs":     createCommitGroup("Documentation"),
+		"test":     createCommitGroup("Tests"),
+		"revert":   createCommitGroup("Reverts"),
+		"style":    createCommitGroup("Styles"),
+		"chore":    createCommitGroup("Chores"),
+		"":         createCommitGroup(""),
+	}
+	JIRAIssueRegex = regexp.MustCompile(`\b[A-Z][A-Z0-9_]+-\d+\b`)
+)
+
+func createCommitGroup(title string) *CommitGroup {
+	groupCounter++
+	return &CommitGroup{
+		Title: title,
+		Order: groupCounter,
+	}
+}
+
+type CommitInfo struct {
+	Kind        string
+	Feature     string
+	Message     string
+	ReleaseNote string
+	group       *CommitGroup
+}
+
+type GroupAndCommitInfos struct {
+	group   *CommitGroup
+	commits []string
+}
+
+type CommitGroup struct {
+	Title string
+	Order int
+}
+
+// ParseCommit parses a conventional commit
+// see: https://conventionalcommits.org/
+func ParseCommit(message string) *CommitInfo {
+	answer := &CommitInfo{
+		Message: message,
+	}
+
+	idx := strings.Index(message, ":")
+	if idx > 0 {
+		kind := message[0:idx]
+		if strings.HasSuffix(kind, ")") {
+			ix := strings.Index(kind, "(")
+			if ix > 0 {
+				answer.Feature = strings.TrimSpace(kind[ix+1 : len(kind)-1])
+				kind = strings.TrimSpace(kind[0:ix])
+			}
+		}
+		// a subject such as "Fix foo: bar" has no type as the types are single words
+		if strings.ContainsAny(kind, " \t\n") {
+			answer.Feature = ""
+			return answer
+		}
+		answer.Kind = kind
+		rest := strings.TrimSpace(message[idx+1:])
+
+		answer.Message = rest
+	}
+	return answer
+}
+
+func describeIssueShort(issue *v1alpha1.IssueSummary) string {
+	prefix := ""
+	id := issue.ID
+	if len(id) > 0 {
+		// lets only add the hash prefix for numeric ids
+		_, err := strconv.Atoi(id)
+		if err == nil {
+			prefix = "#"
+		}
+	}
+	return "[" + prefix + issue.ID + "](" + issue.URL + ") "
+}
+
+func describeUser(info *giturl.GitRepository, user *v1alpha1.UserDetails) string {
+	answer := ""
+	if user != nil {
+		userText := ""
+		login := user.Login
+		url := user.URL
+		label := login
+		if label == "" {
+			label = user.Name
+		}
+		if url == "" && login != "" {
+			url = stringhelpers.UrlJoin(info.HostURL(), login)
+		}
+		if url == "" {
+			userText = label
+		} else if label != "" {
+			userText = "[" + label + "](" + url + ")"
+		}
+		if userText != "" {
+			answer = " (" + userText + ")"
+		}
+	}
+	return answer
+}
+
+func describeCommit(info *giturl.GitRepository, cs *v1alpha1.CommitSummary, ci *CommitInfo, issueMap map[string]*v1alpha1.IssueSummary) string {
+	prefix := ""
+	if ci.Feature != "" {
+		prefix = ci.Feature + ": "
+	}
+	message := strings.TrimSpace(ci.Message)
+	lines := strings.Split(message, "\n")
+	text := lines[0]
+	if ci.ReleaseNote != "" {
+		// the release note replaces the subject, keeping any extra lines inside the list item
+		prefix = ""
+		text = strings.Join(strings.Split(ci.ReleaseNote, "\n"), "\n  ")
+	}
+
+	// TODO add link to issue etc...
+	user := cs.Author
+	if user == nil {
+		user = cs.Committer
+	}
+	issueText := ""
+	for k := range cs.IssueIDs {
+		issue := issueMap[cs.IssueIDs[k]]
+		if issue != nil {
+			issueText += " " + describeIssueShort(issue)
+		}
+	}
+	return prefix + text + describeUser(info, user) + issueText
+}
+
+// Group returns the group of the commit. Commits without a type or with a type which has no title such as ci are
+// in the group of other changes
+func (c *CommitInfo) Group() *CommitGroup {
+	if c.group == nil {
+		c.group = ConventionalCommitTitles[strings.ToLower(c.Kind)]
+	}
+	if c.group == nil {
+		c.group = ConventionalCommitTitles[""]
+	}
+	return c.group
+}
+
+func (c *CommitInfo) Title() string {
+	return c.Group().Title
+}
+
+func (c *CommitInfo) Order() int {
+	return c.Group().Order
+}
@@ -0,0 +1,209 @@
+package changelog
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/config"
+	"github.com/shuttlerock/changlog/pkg/users"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const (
+	// ContributorsAnnotation the annotation of the JSON list of the contributors to the release
+	ContributorsAnnotation = AnnotationPrefix + "contributors"
+)
+
+// CoAuthorRegex matches the co-author trailers of a commit message
+var CoAuthorRegex = regexp.MustCompile(`(?mi)^co-authored-by:\s*(.*?)\s*<([^>]*)>\s*$`)
+
+// Contributor a person who authored, co-authored or committed any of the commits of the release
+type Contributor struct {
+	Login string `json:"login,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	URL   string `json:"url,omitempty"`
+
+	// Commits the number of commits of the release the person contributed to
+	Commits int `json:"commits"`
+
+	// FirstTime whether the first commit of the person in the history of the repository is in the release
+	FirstTime bool `json:"firstTime,omitempty"`
+}
+
+// compileBots compiles the regular expression of the bot accounts defaulting to the default one
+func compileBots(cfg *config.Config) (*regexp.Regexp, error) {
+	pattern := config.DefaultBots
+	if cfg != nil && cfg.Bots != "" {
+		pattern = cfg.Bots
+	}
+	bots, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the bots regex %s", pattern)
+	}
+	return bots, nil
+}
+
+// addContributors finds the contributors of the commits of the release leaving out bots and annotates the release
+// with them
+func (g *Generator) addContributors() error {
+	bots := g.Rules.bots
+	release := g.result.Release
+	commits := append([]v1alpha1.CommitSummary(nil), release.Spec.Commits...)
+	for _, nested := range g.result.NestedCommits {
+		commits = append(commits, nested...)
+	}
+
+	identities := users.NewIdentities()
+	for k := range commits {
+		for _, u := range commitContributors(&commits[k]) {
+			identities.Add(u)
+		}
+	}
+
+	contributors := map[string]*Contributor{}
+	aliases := map[string]map[string]bool{}
+	for k := range commits {
+		counted := map[string]bool{}
+		for _, u := range commitContributors(&commits[k]) {
+			person := identities.Canonical(u)
+			key := contributorKey(person)
+			if key == "" || isBot(bots, u) || isBot(bots, person) {
+				continue
+			}
+			if aliases[key] == nil {
+				aliases[key] = map[string]bool{}
+			}
+			for _, alias := range []string{u.Email, u.Name, person.Email, person.Name} {
+				if alias != "" {
+					aliases[key][strings.ToLower(alias)] = true
+				}
+			}
+			if counted[key] {
+				continue
+			}
+			counted[key] = true
+			c := contributors[key]
+			if c == nil {
+				c = &Contributor{}
+				contributors[key] = c
+			}
+			c.Login = person.Login
+			c.Name = person.Name
+			c.Email = person.Email
+			c.URL = person.URL
+			c.Commits++
+		}
+	}
+	if len(contributors) == 0 {
+		return nil
+	}
+
+	previous, err := g.previousContributors()
+	if err != nil {
+		return err
+	}
+	var answer []Contributor
+	for key, c := range contributors {
+		c.FirstTime = true
+		for alias := range aliases[key] {
+			if previous[alias] {
+				c.FirstTime = false
+				break
+			}
+		}
+		answer = append(answer, *c)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		if answer[i].Commits != answer[j].Commits {
+			return answer[i].Commits > answer[j].Commits
+		}
+		return strings.ToLower(contributorLabel(&answer[i])) < strings.ToLower(contributorLabel(&answer[j]))
+	})
+	g.result.Contributors = answer
+
+	return setJSONAnnotation(release, ContributorsAnnotation, answer)
+}
+
+// previousContributors returns the lower case emails and names of the authors, co-authors and committers of the
+// commits up to and including the previous release
+func (g *Generator) previousContributors() (map[string]bool, error) {
+	from, err := ResolveCommit(g.Repository, g.From)
+	if err != nil {
+		return nil, err
+	}
+	answer := map[string]bool{}
+	add := func(name, email string) {
+		if name != "" {
+			answer[strings.ToLower(name)] = true
+		}
+		if email != "" {
+			answer[strings.ToLower(email)] = true
+		}
+	}
+	err = object.NewCommitPreorderIter(from, nil, nil).ForEach(func(c *object.Commit) error {
+		add(c.Author.Name, c.Author.Email)
+		add(c.Committer.Name, c.Committer.Email)
+		for _, m := range CoAuthorRegex.FindAllStringSubmatch(c.Message, -1) {
+			add(m[1], m[2])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to walk the history of %s", g.From)
+	}
+	return answer, nil
+}
+
+// commitContributors returns the author, committer and co-authors of the commit
+func commitContributors(commit *v1alpha1.CommitSummary) []*v1alpha1.UserDetails {
+	var answer []*v1alpha1.UserDetails
+	for _, u := range []*v1alpha1.UserDetails{commit.Author, commit.Committer} {
+		if u != nil {
+			answer = append(answer, u)
+		}
+	}
+	for _, m := range CoAuthorRegex.FindAllStringSubmatch(commit.Message, -1) {
+		if m[1] != "" || m[2] != "" {
+			answer = append(answer, &v1alpha1.UserDetails{
+				Name:  m[1],
+				Email: m[2],
+			})
+		}
+	}
+	return answer
+}
+
+// contributorKey returns the key identifying the person
+func contributorKey(u *v1alpha1.UserDetails) string {
+	for _, key := range []string{u.Login, u.Email, u.Name} {
+		if key != "" {
+			return strings.ToLower(key)
+		}
+	}
+	return ""
+}
+
+// isBot returns true if the login, name or email of the user matches the bots regex
+func isBot(bots *regexp.Regexp, u *v1alpha1.UserDetails) bool {
+	for _, text := range []string{u.Login, u.Name, u.Email} {
+		if text != "" && bots.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// contributorLabel returns the login of the contributor or else their name
+func contributorLabel(c *Contributor) string {
+	if c.Login != "" {
+		return c.Login
+	}
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Email
+}
@@ -0,0 +1,83 @@
+package changelog
+
+import (
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+)
+
+const (
+	// DependencyCommitsAnnotation the annotation of the JSON object of the SHAs of the commits nested into the release
+	// from each chart dependency indexed by the name of the dependency
+	DependencyCommitsAnnotation = AnnotationPrefix + "dependency-commits"
+)
+
+// DependencyUpdate a chart dependency whose version changed in the release along with the release of the
+// dependency if its repository is available locally
+type DependencyUpdate struct {
+	Name        string
+	FromVersion string
+	ToVersion   string
+	Release     *v1alpha1.Release
+}
+
+// MergeDependencyRelease nests the commits, issues and pull requests of a dependency into the release. Pull request
+// numbers are prefixed with the dependency name as they belong to another repository
+func MergeDependencyRelease(release *v1alpha1.Release, name string, dependency *v1alpha1.Release) {
+	spec := &release.Spec
+	prIDs := map[string]string{}
+	for _, pr := range dependency.Spec.PullRequests {
+		id := name + "#" + pr.ID
+		prIDs[pr.ID] = id
+		pr.ID = id
+		if findIssueSummary(spec.PullRequests, id) == nil {
+			spec.PullRequests = append(spec.PullRequests, pr)
+		}
+	}
+	for _, issue := range dependency.Spec.Issues {
+		if findIssueSummary(spec.Issues, issue.ID) == nil {
+			spec.Issues = append(spec.Issues, issue)
+		}
+	}
+	for _, c := range dependency.Spec.Commits {
+		var issueIDs []string
+		for _, id := range c.IssueIDs {
+			if prID, ok := prIDs[id]; ok {
+				id = prID
+			}
+			issueIDs = append(issueIDs, id)
+		}
+		c.IssueIDs = issueIDs
+		spec.Commits = append(spec.Commits, c)
+	}
+}
+
+// AddDependencyCommitsAnnotation annotates the release of the result with the commits of each chart dependency so that
+// they can be told apart from the commits of the umbrella chart
+func AddDependencyCommitsAnnotation(result *Result) error {
+	commits := map[string][]string{}
+	for _, u := range result.DependencyUpdates {
+		if u.Release == nil {
+			continue
+		}
+		for _, c := range u.Release.Spec.Commits {
+			commits[u.Name] = append(commits[u.Name], c.SHA)
+		}
+	}
+	if len(commits) == 0 {
+		return nil
+	}
+	return setJSONAnnotation(result.Release, DependencyCommitsAnnotation, commits)
+}
+
+// dependencyCommits returns the name of the chart dependency of each commit nested from a dependency indexed by SHA
+func (r *Result) dependencyCommits() map[string]string {
+	answer := map[string]string{}
+	for _, u := range r.DependencyUpdates {
+		if u.Release == nil {
+			continue
+		}
+		for _, c := range u.Release.Spec.Commits {
+			answer[c.SHA] = u.Name
+		}
+	}
+	return answer
+}
@@ -0,0 +1,124 @@
+package changelog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/shuttlerock/changlog/pkg/tracker"
+)
+
+const (
+	// DiagnosticIssueLookupFailed the issue tracker failed to look up an issue
+	DiagnosticIssueLookupFailed = "issue-lookup-failed"
+
+	// DiagnosticIssueNotFound an issue key in a commit message is unknown to the issue tracker
+	DiagnosticIssueNotFound = "issue-not-found"
+
+	// DiagnosticIssueDetailsFailed the issue tracker failed to return the details of an issue such as its type
+	DiagnosticIssueDetailsFailed = "issue-details-failed"
+
+	// DiagnosticUserNotResolved a commit, issue or pull request user could not be resolved
+	DiagnosticUserNotResolved = "user-not-resolved"
+
+	// DiagnosticClosedByMissing the issue tracker did not return who closed an issue
+	DiagnosticClosedByMissing = "closed-by-missing"
+
+	// DiagnosticAssigneesMissing the issue tracker did not return the assignees of an issue
+	DiagnosticAssigneesMissing = "assignees-missing"
+
+	// DiagnosticPullRequestLookupFailed the git provider failed to look up a pull request
+	DiagnosticPullRequestLookupFailed = "pull-request-lookup-failed"
+
+	// DiagnosticPullRequestNotFound the git provider did not find a pull request
+	DiagnosticPullRequestNotFound = "pull-request-not-found"
+
+	// DiagnosticIssueKeyOutsideProject a key in a commit message such as UTF-8 which looks like the key of an issue
+	// of another project was not found in the issue tracker
+	DiagnosticIssueKeyOutsideProject = "issue-key-outside-project"
+)
+
+// Diagnostic a problem enriching the release with the details of a commit, issue or pull request
+type Diagnostic struct {
+	// Kind the kind of problem such as issue-not-found
+	Kind string `json:"kind"`
+
+	// Commit the SHA of the commit being enriched if known
+	Commit string `json:"commit,omitempty"`
+
+	// Issue the key of the issue or number of the pull request if any
+	Issue string `json:"issue,omitempty"`
+
+	// Cause the description of the problem
+	Cause string `json:"cause"`
+}
+
+// DiagnosticsReport the machine readable report of the problems enriching the release
+type DiagnosticsReport struct {
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// warnf logs the warning and records it as a diagnostic of the result
+func (g *Generator) warnf(kind, sha, issue, format string, args ...interface{}) {
+	cause := fmt.Sprintf(format, args...)
+	log.Logger().Warn(cause)
+	g.result.Diagnostics = append(g.result.Diagnostics, Diagnostic{
+		Kind:   kind,
+		Commit: sha,
+		Issue:  issue,
+		Cause:  cause,
+	})
+}
+
+// missingIssuef records that the issue could not be found, downgrading it to a debug message if the key is not in
+// any of the issue projects as it is most likely not an issue key at all
+func (g *Generator) missingIssuef(kind, sha, issue, format string, args ...interface{}) {
+	if g.inIssueProjects(issue) {
+		g.warnf(kind, sha, issue, format, args...)
+		return
+	}
+	cause := fmt.Sprintf(format, args...)
+	log.Logger().Debug(cause)
+	g.result.Diagnostics = append(g.result.Diagnostics, Diagnostic{
+		Kind:   DiagnosticIssueKeyOutsideProject,
+		Commit: sha,
+		Issue:  issue,
+		Cause:  cause,
+	})
+}
+
+// inIssueProjects returns true if the issue key belongs to one of the configured projects or else the project of
+// the Jira issue tracker. Any key belongs to them if there are none
+func (g *Generator) inIssueProjects(key string) bool {
+	projects := g.Config.IssueProjects
+	if len(projects) == 0 {
+		if provider, ok := g.Tracker.(*tracker.JiraProvider); ok && provider.JiraService != nil && provider.Project != "" {
+			projects = []string{provider.Project}
+		}
+	}
+	if len(projects) == 0 {
+		return true
+	}
+	project := key
+	if idx := strings.LastIndex(key, "-"); idx > 0 {
+		project = key[:idx]
+	}
+	for _, p := range projects {
+		if strings.EqualFold(strings.TrimSpace(p), project) {
+			return true
+		}
+	}
+	return false
+}
+
+// StrictDiagnostics returns the diagnostics which fail the run in strict mode which are the missing or unknown
+// issue keys of the issue projects
+func StrictDiagnostics(diagnostics []Diagnostic) []Diagnostic {
+	var answer []Diagnostic
+	for _, d := range diagnostics {
+		if d.Kind == DiagnosticIssueLookupFailed || d.Kind == DiagnosticIssueNotFound {
+			answer = append(answer, d)
+		}
+	}
+	return answer
+}
@@ -0,0 +1,22 @@
+package changelog
+
+import (
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+)
+
+const (
+	// EnrichmentAnnotation the annotation recording whether the release was enriched with the details of its pull
+	// requests, issues and users from the git provider and issue tracker
+	EnrichmentAnnotation = AnnotationPrefix + "enrichment"
+
+	// EnrichmentUnavailable the value of the enrichment annotation when the release was generated offline
+	EnrichmentUnavailable = "unavailable"
+)
+
+// markEnrichmentUnavailable annotates the release as not being enriched by the git provider or issue tracker
+func markEnrichmentUnavailable(release *v1alpha1.Release) {
+	if release.Annotations == nil {
+		release.Annotations = map[string]string{}
+	}
+	release.Annotations[EnrichmentAnnotation] = EnrichmentUnavailable
+}
@@ -0,0 +1,111 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/config"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// ExcludedCommit a commit which was dropped from the release along with the reason why
+type ExcludedCommit struct {
+	SHA     string
+	Subject string
+	Reason  string
+}
+
+// commitExclusion a compiled exclusion rule
+type commitExclusion struct {
+	rule    config.ExclusionRule
+	subject *regexp.Regexp
+	author  *regexp.Regexp
+}
+
+// compileExclusions compiles the regular expressions of the exclusion rules
+func compileExclusions(rules []config.ExclusionRule) ([]*commitExclusion, error) {
+	var answer []*commitExclusion
+	for i := range rules {
+		e := &commitExclusion{
+			rule: rules[i],
+		}
+		var err error
+		if e.rule.Subject != "" {
+			e.subject, err = regexp.Compile(e.rule.Subject)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse subject regex of exclusion rule %d", i+1)
+			}
+		}
+		if e.rule.Author != "" {
+			e.author, err = regexp.Compile(e.rule.Author)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse author regex of exclusion rule %d", i+1)
+			}
+		}
+		answer = append(answer, e)
+	}
+	return answer, nil
+}
+
+// matches returns true if the commit matches all of the conditions of the rule
+func (e *commitExclusion) matches(commit *object.Commit) bool {
+	if e.subject == nil && e.author == nil && e.rule.Trailer == "" && len(e.rule.Types) == 0 {
+		return false
+	}
+	subject := strings.TrimSpace(strings.SplitN(commit.Message, "\n", 2)[0])
+	if e.subject != nil && !e.subject.MatchString(subject) {
+		return false
+	}
+	if e.author != nil && !e.author.MatchString(commit.Author.Name) && !e.author.MatchString(commit.Author.Email) {
+		return false
+	}
+	if e.rule.Trailer != "" && !strings.Contains(strings.ToLower(commit.Message), strings.ToLower(e.rule.Trailer)) {
+		return false
+	}
+	if len(e.rule.Types) > 0 && !ContainsAnyLabel(e.rule.Types, []string{ParseCommit(subject).Kind}) {
+		return false
+	}
+	return true
+}
+
+// description describes the rule for the listing of excluded commits
+func (e *commitExclusion) description() string {
+	if e.rule.Name != "" {
+		return e.rule.Name
+	}
+	var conditions []string
+	if e.rule.Subject != "" {
+		conditions = append(conditions, fmt.Sprintf("subject matches %q", e.rule.Subject))
+	}
+	if e.rule.Author != "" {
+		conditions = append(conditions, fmt.Sprintf("author matches %q", e.rule.Author))
+	}
+	if e.rule.Trailer != "" {
+		conditions = append(conditions, fmt.Sprintf("message contains %q", e.rule.Trailer))
+	}
+	if len(e.rule.Types) > 0 {
+		conditions = append(conditions, fmt.Sprintf("type is one of %s", strings.Join(e.rule.Types, ", ")))
+	}
+	return strings.Join(conditions, " and ")
+}
+
+// excludeCommit returns true if the commit should be dropped from the release, recording the reason why
+func (g *Generator) excludeCommit(commit *object.Commit) bool {
+	for _, e := range g.Rules.exclusions {
+		if e.matches(commit) {
+			g.recordExcludedCommit(commit, e.description())
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) recordExcludedCommit(commit *object.Commit, reason string) {
+	g.result.ExcludedCommits = append(g.result.ExcludedCommits, ExcludedCommit{
+		SHA:     commit.Hash.String(),
+		Subject: strings.TrimSpace(strings.SplitN(commit.Message, "\n", 2)[0]),
+		Reason:  reason,
+	})
+}
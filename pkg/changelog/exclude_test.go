@@ -0,0 +1,110 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shuttlerock/changlog/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestExcludeCommit(t *testing.T) {
+	rules, err := CompileRules(&config.Config{
+		Exclude: []config.ExclusionRule{
+			{Name: "dependency bumps", Author: `dependabot\[bot\]`},
+			{Subject: `^Merge branch`},
+			{Trailer: "[skip changelog]"},
+			{Types: []string{"chore", "test"}},
+			{Subject: `^docs`, Author: `@example\.com$`},
+			{},
+		},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		message  string
+		author   string
+		email    string
+		expected string
+	}{
+		{
+			name:     "author name",
+			message:  "fix: bump the go-scm version",
+			author:   "dependabot[bot]",
+			email:    "49699333+dependabot[bot]@users.noreply.github.com",
+			expected: "dependency bumps",
+		},
+		{
+			name:     "subject",
+			message:  "Merge branch 'main' into feature",
+			expected: `subject matches "^Merge branch"`,
+		},
+		{
+			name:     "trailer ignoring case",
+			message:  "fix: typo\n\n[Skip Changelog]",
+			expected: `message contains "[skip changelog]"`,
+		},
+		{
+			name:     "conventional commit type",
+			message:  "chore(deps): tidy the modules",
+			expected: "type is one of chore, test",
+		},
+		{
+			name:     "all of the conditions",
+			message:  "docs: explain the flags",
+			email:    "jane@example.com",
+			expected: `subject matches "^docs" and author matches "@example\\.com$"`,
+		},
+		{
+			name:    "only some of the conditions",
+			message: "docs: explain the flags",
+			email:   "jane@acme.com",
+		},
+		{
+			name:    "no rule matches and the empty rule matches nothing",
+			message: "feat: add the issues view",
+			author:  "jane",
+			email:   "jane@acme.com",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := &Generator{
+				Rules:  rules,
+				result: &Result{},
+			}
+			commit := &object.Commit{
+				Message: tc.message,
+				Author: object.Signature{
+					Name:  tc.author,
+					Email: tc.email,
+				},
+			}
+			excluded := g.excludeCommit(commit)
+			if tc.expected == "" {
+				assert.False(t, excluded)
+				assert.Empty(t, g.result.ExcludedCommits)
+				return
+			}
+			assert.True(t, excluded)
+			require.Len(t, g.result.ExcludedCommits, 1)
+			assert.Equal(t, tc.expected, g.result.ExcludedCommits[0].Reason)
+			assert.Equal(t, strings.SplitN(tc.message, "\n", 2)[0], g.result.ExcludedCommits[0].Subject)
+		})
+	}
+}
+
+func TestCompileRulesInvalidRegex(t *testing.T) {
+	_, err := CompileRules(&config.Config{
+		Exclude: []config.ExclusionRule{
+			{Subject: "^chore"},
+			{Author: "dependabot[bot"},
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid changelog configuration")
+	assert.Contains(t, err.Error(), "exclusion rule 2")
+}
@@ -0,0 +1,146 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// GitLabMergeRequestRegex matches the trailer GitLab adds to the message of a merge commit
+var GitLabMergeRequestRegex = regexp.MustCompile(`(?m)^See merge request \S+!(\d+)\s*$`)
+
+// fetchFirstParentCommits returns the commits on the mainline between the two revisions by only following the first
+// parent of each commit, so that each merge commit stands in for the commits of the branch it merged
+func fetchFirstParentCommits(repo *git.Repository, fromRev, toRev string) ([]object.Commit, error) {
+	fromCommit, err := ResolveCommit(repo, fromRev)
+	if err != nil {
+		return nil, err
+	}
+	toCommit, err := ResolveCommit(repo, toRev)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := ancestors(fromCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	var answer []object.Commit
+	for c := toCommit; c != nil && !previous[c.Hash]; {
+		answer = append(answer, *c)
+		if c.NumParents() == 0 {
+			break
+		}
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find the first parent of %s", c.Hash.String())
+		}
+		c = parent
+	}
+	return answer, nil
+}
+
+// ResolveCommit returns the commit the revision refers to
+func ResolveCommit(repo *git.Repository, rev string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve git revision %s", rev)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find git commit %s", hash.String())
+	}
+	return commit, nil
+}
+
+// mergeRequestNumber returns the number of the pull or merge request merged by a merge commit
+func mergeRequestNumber(message string) int {
+	numbers := pullRequestNumbersFromMessage(message)
+	if len(numbers) > 0 {
+		return numbers[0]
+	}
+	match := GitLabMergeRequestRegex.FindStringSubmatch(message)
+	if len(match) > 1 {
+		n, err := strconv.Atoi(match[1])
+		if err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// mergeCommitTitle returns the title of the pull request a merge commit merged along with the rest of the body of
+// the pull request. GitHub and GitLab put the title on the first line of the body of the merge commit message
+func mergeCommitTitle(message string) (string, string) {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	for i, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line != "" && !GitLabMergeRequestRegex.MatchString(line) {
+			body := GitLabMergeRequestRegex.ReplaceAllString(strings.Join(lines[i+2:], "\n"), "")
+			return line, strings.TrimSpace(body)
+		}
+	}
+	return lines[0], ""
+}
+
+// addMergeCommit adds a merge commit as a single entry with the title and number of the pull request it merged,
+// keeping the body of the pull request for its release note and trailers. The commits of the merged branch are kept
+// so that they can be nested under the entry
+func (g *Generator) addMergeCommit(spec *v1alpha1.ReleaseSpec, commit *object.Commit) {
+	commitSummary := g.toCommitSummary(commit)
+
+	title, body := mergeCommitTitle(commit.Message)
+	n := mergeRequestNumber(commit.Message)
+	if n > 0 {
+		g.addPullRequestNumbers(spec, &commitSummary, []int{n})
+		pr := findIssueSummary(spec.PullRequests, strconv.Itoa(n))
+		if pr != nil && pr.Title != "" {
+			title = pr.Title
+		}
+		title = fmt.Sprintf("%s (#%d)", title, n)
+	}
+	commitSummary.Message = title
+	if body != "" {
+		commitSummary.Message += "\n\n" + body
+	}
+	g.addIssuesAndPullRequests(spec, &commitSummary, commit)
+
+	if g.NestMergedCommits && commit.NumParents() > 1 {
+		branchCommits, err := FetchCommits(g.Repository, commit.ParentHashes[0].String(), commit.ParentHashes[1].String())
+		if err != nil {
+			log.Logger().Warnf("failed to find the merged commits of %s: %v", commit.Hash.String(), err)
+		}
+		// the commits of the branch are filtered the same way as the commits of the mainline
+		duplicates := findCherryPickDuplicates(branchCommits)
+		for k := range branchCommits {
+			c := branchCommits[k]
+			if len(c.ParentHashes) > 1 {
+				continue
+			}
+			if original, ok := duplicates[c.Hash.String()]; ok {
+				g.recordExcludedCommit(&c, "same change as commit "+original)
+				continue
+			}
+			if g.excludeCommit(&c) || !g.changesPaths(&c) {
+				continue
+			}
+			g.addCherryPick(&c)
+			nested := g.toCommitSummary(&c)
+			g.addIssuesAndPullRequests(spec, &nested, &c)
+			if g.result.NestedCommits == nil {
+				g.result.NestedCommits = map[string][]v1alpha1.CommitSummary{}
+			}
+			g.result.NestedCommits[commitSummary.SHA] = append(g.result.NestedCommits[commitSummary.SHA], nested)
+		}
+	}
+	spec.Commits = append(spec.Commits, commitSummary)
+}
@@ -0,0 +1,72 @@
+package changelog_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/cli"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+	"github.com/shuttlerock/changlog/pkg/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirstParentMergeCommit(t *testing.T) {
+	g := cli.NewCLIClient("", nil)
+	dir, shas := gittest.CreateRepository(t, g, gittest.Commit{
+		Message: "chore: initial commit",
+		Files:   map[string]string{"app/main.txt": "main\n", "docs/index.md": "index\n"},
+	})
+
+	date := gittest.FirstCommitTime
+	git := func(args ...string) string {
+		date += 60
+		t.Setenv("GIT_AUTHOR_DATE", fmt.Sprintf("%d +0000", date))
+		t.Setenv("GIT_COMMITTER_DATE", fmt.Sprintf("%d +0000", date))
+		return gittest.Run(t, g, dir, args...)
+	}
+	commit := func(file, text, message string) string {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, file), []byte(text), 0o600))
+		git("add", "-A")
+		git("commit", "-q", "-m", message)
+		return git("rev-parse", "HEAD")
+	}
+
+	git("checkout", "-q", "-b", "feature")
+	feature := commit("app/view.txt", "view\n", "feat: add the issues view")
+	docs := commit("docs/view.md", "view\n", "docs: explain the issues view")
+	cherryPick := commit("app/again.txt", "again\n", "feat: add the issues view again\n\n(cherry picked from commit "+feature+")")
+	git("checkout", "-q", "-")
+	git("merge", "-q", "--no-ff", "feature", "-m", "Merge pull request #1 from acme/feature\n\nAdd the issues view\n\nLists the commits under their issues.\n\nRelease-Note: Add the issues view")
+	merge := git("rev-parse", "HEAD")
+
+	generator := &changelog.Generator{
+		Dir:               dir,
+		From:              shas[0],
+		To:                merge,
+		Paths:             []string{"app"},
+		FirstParent:       true,
+		NestMergedCommits: true,
+		Offline:           true,
+	}
+	result, err := generator.Generate()
+	require.NoError(t, err)
+
+	spec := result.Release.Spec
+	require.Len(t, spec.Commits, 1)
+	assert.Equal(t, merge, spec.Commits[0].SHA)
+	assert.Equal(t, "Add the issues view (#1)\n\nLists the commits under their issues.\n\nRelease-Note: Add the issues view", spec.Commits[0].Message, "the body of the pull request is kept")
+
+	nested := result.NestedCommits[merge]
+	require.Len(t, nested, 1, "the commits outside the paths and cherry-picks are not nested")
+	assert.Equal(t, feature, nested[0].SHA)
+
+	reasons := map[string]string{}
+	for _, excluded := range result.ExcludedCommits {
+		reasons[excluded.SHA] = excluded.Reason
+	}
+	assert.Equal(t, "outside the source paths app", reasons[docs])
+	assert.Equal(t, "same change as commit "+feature, reasons[cherryPick])
+}
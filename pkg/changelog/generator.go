@@ -0,0 +1,531 @@
+package changelog
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x-plugins/jx-changelog/pkg/issues"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/kube"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/config"
+	"github.com/shuttlerock/changlog/pkg/retry"
+	"github.com/shuttlerock/changlog/pkg/tracker"
+	"github.com/shuttlerock/changlog/pkg/users"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ReleaseName = `{{ .Chart.Name }}-{{ .Chart.Version | replace "+" "_" }}`
+	SpecName    = `{{ .Chart.Name }}`
+)
+
+// Generator generates the Release of the changes between two revisions of a git repository.
+// It only uses its explicit inputs so that it can be embedded in other tools, rendering and writing the Result are
+// separate steps
+type Generator struct {
+	// Dir the directory of the git repository, only used if Repository is nil
+	Dir string
+
+	// Repository the git repository
+	Repository *git.Repository
+
+	// From the revision of the previous release which is excluded from the changes
+	From string
+
+	// To the revision of the release
+	To string
+
+	// Tracker the issue tracker to look up the issues referenced by the commits. Issues are not looked up if nil
+	Tracker issues.IssueProvider
+
+	// Resolver resolves git users to users of the git provider. Defaults to a resolver using the ScmClient or, if
+	// there is none, one which resolves users from their git signatures alone
+	Resolver *users.GitUserResolver
+
+	// ScmClient the git provider client to look up pull requests. Pull requests are not looked up if nil
+	ScmClient *scm.Client
+
+	// Owner the owner of the repository on the git provider
+	Owner string
+
+	// RepositoryName the name of the repository on the git provider
+	RepositoryName string
+
+	// Config the changelog configuration. Defaults to the default configuration
+	Config *config.Config
+
+	// Rules the compiled exclusion rules and bots of the Config. Compiled from the Config if nil
+	Rules *Rules
+
+	// FirstParent only follows the first parent of commits so that each merge commit is one entry
+	FirstParent bool
+
+	// NestMergedCommits keeps the commits of each merged branch when using FirstParent
+	NestMergedCommits bool
+
+	// Paths only includes the commits which change these paths relative to the root of the repository if not empty
+	Paths []string
+
+	// Created the creation time of the Release. Defaults to the commit time of the To revision
+	Created time.Time
+
+	// Offline skips looking up pull requests and users on the git provider and annotates the Release as not being
+	// enriched, as it is when there is neither a ScmClient nor a Tracker. Issues are only looked up if the Tracker is
+	// local such as an issues file
+	Offline bool
+
+	// Retry how the calls to the git provider and issue tracker are timed out and retried
+	Retry retry.Options
+
+	ctx               context.Context
+	teams             *teamResolver
+	foundIssueNames   map[string]bool
+	foundPullRequests map[int]bool
+	commitLogins      map[string]string
+	result            *Result
+}
+
+// Result the Release generated from the changes along with the details needed to render it
+type Result struct {
+	// Release the generated Release resource
+	Release *v1alpha1.Release
+
+	// ExcludedCommits the commits left out of the Release and why
+	ExcludedCommits []ExcludedCommit
+
+	// NestedCommits the commits of the branch merged by each merge commit indexed by the SHA of the merge commit
+	NestedCommits map[string][]v1alpha1.CommitSummary
+
+	// DependencyUpdates the chart dependencies whose version changed
+	DependencyUpdates []DependencyUpdate
+
+	// Diagnostics the problems looking up the issues, pull requests and users of the commits
+	Diagnostics []Diagnostic
+
+	// Contributors the people who contributed to the commits other than bots, most commits first
+	Contributors []Contributor
+
+	// CherryPicks the SHA of the commit each cherry-picked commit was cherry-picked from indexed by the SHA of the
+	// cherry-picked commit
+	CherryPicks map[string]string
+
+	// CommitTeams the teams of each commit indexed by the SHA of the commit
+	CommitTeams map[string][]string
+
+	// IssueDetails the details of the issues such as their type and epic indexed by the issue key if the issue
+	// tracker supports them
+	IssueDetails map[string]*tracker.Details
+}
+
+// NewRelease creates an empty Release resource created at the given time
+func NewRelease(created time.Time) *v1alpha1.Release {
+	return &v1alpha1.Release{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Release",
+			APIVersion: v1alpha1.GroupVersion.Group + "/" + v1alpha1.GroupVersion.Version,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ReleaseName,
+			CreationTimestamp: metav1.Time{
+				Time: created,
+			},
+		},
+		Spec: v1alpha1.ReleaseSpec{
+			//Name:          SpecName,
+			//Version:       version,
+			//GitOwner:      gitInfo.Organisation,
+			//GitRepository: gitInfo.Name,
+			//GitHTTPURL:    gitInfo.HttpsURL(),
+			//GitCloneURL:   gitInfo.CloneURL,
+			//Commits:       []v1alpha1.CommitSummary{},
+			Issues:       []v1alpha1.IssueSummary{},
+			PullRequests: []v1alpha1.IssueSummary{},
+		},
+	}
+}
+
+// Generate generates the Release of the commits between the two revisions
+func (g *Generator) Generate() (*Result, error) {
+	return g.GenerateContext(context.Background())
+}
+
+// GenerateContext generates the Release of the commits between the two revisions. The calls to the git provider and
+// issue tracker stop when the context is done
+func (g *Generator) GenerateContext(ctx context.Context) (*Result, error) {
+	g.ctx = ctx
+	if g.From == "" || g.To == "" {
+		return nil, errors.Errorf("the revisions to generate the changelog between must be specified")
+	}
+	if g.Repository == nil {
+		repo, err := git.PlainOpen(g.Dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to open git repository %s", g.Dir)
+		}
+		g.Repository = repo
+	}
+	if g.Config == nil {
+		g.Config = &config.Config{}
+		g.Config.Defaults()
+	}
+	var err error
+	if g.Rules == nil {
+		g.Rules, err = CompileRules(g.Config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	g.teams, err = g.loadTeams()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the teams")
+	}
+	if g.Offline {
+		g.ScmClient = nil
+	}
+	if g.Resolver == nil {
+		if g.ScmClient == nil {
+			g.Resolver = users.NewNoProviderResolver()
+		} else {
+			g.Resolver = &users.GitUserResolver{
+				GitProvider: g.ScmClient,
+				Context:     ctx,
+				Retry:       g.Retry,
+			}
+		}
+	}
+	created := g.Created
+	if created.IsZero() {
+		to, err := ResolveCommit(g.Repository, g.To)
+		if err != nil {
+			return nil, err
+		}
+		created = to.Committer.When.UTC()
+	}
+
+	g.foundIssueNames = map[string]bool{}
+	g.foundPullRequests = map[int]bool{}
+	g.commitLogins = map[string]string{}
+	g.result = &Result{
+		Release: NewRelease(created),
+	}
+	err = g.addCommits(g.result.Release)
+	if err != nil {
+		return nil, err
+	}
+	mergeIdentities(&g.result.Release.Spec)
+	err = g.addCherryPicksAnnotation()
+	if err != nil {
+		return nil, err
+	}
+	err = g.addTeamsAnnotation()
+	if err != nil {
+		return nil, err
+	}
+	err = g.addIssueDetailsAnnotation()
+	if err != nil {
+		return nil, err
+	}
+	err = g.addContributors()
+	if err != nil {
+		return nil, err
+	}
+	if g.Offline || (g.ScmClient == nil && g.Tracker == nil) {
+		markEnrichmentUnavailable(g.result.Release)
+	}
+	SortRelease(&g.result.Release.Spec)
+	return g.result, nil
+}
+
+// FetchCommits returns the commits reachable from the to revision which are not reachable from the from revision,
+// most recent first
+func FetchCommits(repo *git.Repository, fromRev, toRev string) ([]object.Commit, error) {
+	fromCommit, err := ResolveCommit(repo, fromRev)
+	if err != nil {
+		return nil, err
+	}
+	toCommit, err := ResolveCommit(repo, toRev)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := ancestors(fromCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	var answer []object.Commit
+	seen := map[plumbing.Hash]bool{toCommit.Hash: true}
+	queue := []*object.Commit{toCommit}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if previous[c.Hash] {
+			continue
+		}
+		answer = append(answer, *c)
+		err = c.Parents().ForEach(func(parent *object.Commit) error {
+			if !seen[parent.Hash] {
+				seen[parent.Hash] = true
+				queue = append([]*object.Commit{parent}, queue...)
+			}
+			return nil
+		})
+		if err != nil && err != plumbing.ErrObjectNotFound {
+			return nil, errors.Wrapf(err, "failed to walk the parents of %s", c.Hash.String())
+		}
+	}
+	return answer, nil
+}
+
+// ancestors returns the commit and all of its ancestors
+func ancestors(commit *object.Commit) (map[plumbing.Hash]bool, error) {
+	answer := map[plumbing.Hash]bool{}
+	err := object.NewCommitPreorderIter(commit, nil, nil).ForEach(func(c *object.Commit) error {
+		answer[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to walk the history of %s", commit.Hash.String())
+	}
+	return answer, nil
+}
+
+// addCommits adds the commits between the two revisions to the release
+func (g *Generator) addCommits(release *v1alpha1.Release) error {
+	var err error
+	var commits []object.Commit
+	if g.FirstParent {
+		commits, err = fetchFirstParentCommits(g.Repository, g.From, g.To)
+		if err != nil {
+			return errors.Wrapf(err, "failed to find the mainline commits between %s and %s", g.From, g.To)
+		}
+	} else {
+		commits, err = FetchCommits(g.Repository, g.From, g.To)
+		if err != nil {
+			return errors.Wrapf(err, "failed to find the commits between %s and %s", g.From, g.To)
+		}
+	}
+
+	if len(commits) > 0 && strings.HasPrefix(commits[0].Message, "release ") {
+		// remove the release commit from the log
+		g.recordExcludedCommit(&commits[0], "release commit")
+		commits = commits[1:]
+	}
+	log.Logger().Debugf("Found commits:")
+	for k := range commits {
+		commit := commits[k]
+		log.Logger().Debugf("  commit %s", commit.Hash)
+		log.Logger().Debugf("  Author: %s <%s>", commit.Author.Name, commit.Author.Email)
+		log.Logger().Debugf("  Date: %s", commit.Committer.When.Format(time.ANSIC))
+		log.Logger().Debugf("      %s\n\n\n", commit.Message)
+	}
+
+	// the git commits of the release commits in the same order
+	var listed []object.Commit
+	duplicates := findCherryPickDuplicates(commits)
+	for k := range commits {
+		c := commits[k]
+		if original, ok := duplicates[c.Hash.String()]; ok {
+			g.recordExcludedCommit(&c, "same change as commit "+original)
+			continue
+		}
+		if g.excludeCommit(&c) || !g.changesPaths(&c) {
+			continue
+		}
+		g.addCherryPick(&c)
+		if len(c.ParentHashes) <= 1 {
+			g.addCommit(&release.Spec, &c)
+			listed = append(listed, c)
+		} else if g.FirstParent {
+			g.addMergeCommit(&release.Spec, &c)
+			listed = append(listed, c)
+		} else {
+			// merge commits are not listed but still tell us which pull request was merged
+			g.addPullRequests(&release.Spec, nil, &c)
+		}
+	}
+
+	// the logins of the commit authors are only known once the pull requests of all of the commits are found
+	g.addCommitLogins(&release.Spec)
+	for k := range listed {
+		g.addCommitTeams(&release.Spec.Commits[k], &listed[k])
+	}
+	return nil
+}
+
+func (g *Generator) addCommit(spec *v1alpha1.ReleaseSpec, commit *object.Commit) {
+	commitSummary := g.toCommitSummary(commit)
+
+	g.addIssuesAndPullRequests(spec, &commitSummary, commit)
+	g.addPullRequests(spec, &commitSummary, commit)
+	spec.Commits = append(spec.Commits, commitSummary)
+}
+
+// toCommitSummary converts the git commit into a CommitSummary resolving the author and committer
+func (g *Generator) toCommitSummary(commit *object.Commit) v1alpha1.CommitSummary {
+	// TODO
+	url := ""
+	branch := "master"
+
+	var author, committer *v1alpha1.UserDetails
+	var err error
+	sha := commit.Hash.String()
+	if commit.Author.Email != "" && commit.Author.Name != "" {
+		author, err = g.Resolver.GitSignatureAsUser(&commit.Author)
+		if err != nil {
+			g.warnf(DiagnosticUserNotResolved, sha, "", "failed to enrich commit with issues, error getting git signature for git author %s: %v", commit.Author, err)
+		}
+	}
+	if commit.Committer.Email != "" && commit.Committer.Name != "" {
+		committer, err = g.Resolver.GitSignatureAsUser(&commit.Committer)
+		if err != nil {
+			g.warnf(DiagnosticUserNotResolved, sha, "", "failed to enrich commit with issues, error getting git signature for git committer %s: %v", commit.Committer, err)
+		}
+	}
+	return v1alpha1.CommitSummary{
+		Message:   commit.Message,
+		URL:       url,
+		SHA:       sha,
+		Author:    author,
+		Branch:    branch,
+		Committer: committer,
+	}
+}
+
+func (g *Generator) addIssuesAndPullRequests(spec *v1alpha1.ReleaseSpec, commit *v1alpha1.CommitSummary, rawCommit *object.Commit) {
+	tracker := g.Tracker
+	if tracker == nil {
+		return
+	}
+
+	regex := JIRAIssueRegex
+	message := fullCommitMessageText(rawCommit)
+
+	matches := regex.FindAllStringSubmatch(message, -1)
+	sha := rawCommit.Hash.String()
+
+	resolver := g.Resolver
+	for _, match := range matches {
+		for _, result := range match {
+			result = strings.TrimPrefix(result, "#")
+			// later commits referencing an issue which was already found are also linked to it
+			if found, ok := g.foundIssueNames[result]; ok {
+				if found && stringhelpers.StringArrayIndex(commit.IssueIDs, result) < 0 {
+					commit.IssueIDs = append(commit.IssueIDs, result)
+				}
+				continue
+			}
+			g.foundIssueNames[result] = false
+			issue, err := g.getIssue(tracker, result)
+			if err != nil {
+				g.missingIssuef(DiagnosticIssueLookupFailed, sha, result, "Failed to lookup issue %s in issue tracker %s due to %s", result, tracker.HomeURL(), err)
+				continue
+			}
+			if issue == nil {
+				g.missingIssuef(DiagnosticIssueNotFound, sha, result, "Failed to find issue %s for repository %s", result, tracker.HomeURL())
+				continue
+			}
+
+			user, err := resolver.Resolve(&issue.Author)
+			if err != nil {
+				g.warnf(DiagnosticUserNotResolved, sha, result, "Failed to resolve user %v for issue %s repository %s", issue.Author, result, tracker.HomeURL())
+			}
+
+			var closedBy *v1alpha1.UserDetails
+			if issue.ClosedBy == nil {
+				g.warnf(DiagnosticClosedByMissing, sha, result, "Failed to find closedBy user for issue %s repository %s", result, tracker.HomeURL())
+			} else {
+				u, err := resolver.Resolve(issue.ClosedBy)
+				if err != nil {
+					g.warnf(DiagnosticUserNotResolved, sha, result, "Failed to resolve closedBy user %v for issue %s repository %s", issue.ClosedBy, result, tracker.HomeURL())
+				} else if u != nil {
+					closedBy = u
+				}
+			}
+
+			var assignees []v1alpha1.UserDetails
+			if issue.Assignees == nil {
+				g.warnf(DiagnosticAssigneesMissing, sha, result, "Failed to find assignees for issue %s repository %s", result, tracker.HomeURL())
+			} else {
+				u, err := resolver.GitUserSliceAsUserDetailsSlice(issue.Assignees)
+				if err != nil {
+					g.warnf(DiagnosticUserNotResolved, sha, result, "Failed to resolve Assignees %v for issue %s repository %s", issue.Assignees, result, tracker.HomeURL())
+				}
+				assignees = u
+			}
+
+			labels := toV1Labels(issue.Labels)
+			g.foundIssueNames[result] = true
+			commit.IssueIDs = append(commit.IssueIDs, result)
+			issueSummary := v1alpha1.IssueSummary{
+				ID:                result,
+				URL:               issue.Link,
+				Title:             issue.Title,
+				Body:              issue.Body,
+				User:              user,
+				CreationTimestamp: kube.ToMetaTime(&issue.Created),
+				ClosedBy:          closedBy,
+				Assignees:         assignees,
+				Labels:            labels,
+			}
+			state := issue.State
+			if state != "" {
+				issueSummary.State = state
+			}
+			g.addIssueDetails(tracker, sha, &issueSummary)
+			if issue.PullRequest {
+				spec.PullRequests = append(spec.PullRequests, issueSummary)
+			} else {
+				spec.Issues = append(spec.Issues, issueSummary)
+			}
+		}
+	}
+}
+
+// getIssue looks up the issue in the tracker with the timeout and retries of the generator. The issue providers take
+// no context so a lookup which times out is abandoned rather than stopped
+func (g *Generator) getIssue(tracker issues.IssueProvider, key string) (*scm.Issue, error) {
+	var issue *scm.Issue
+	err := retry.Do(g.ctx, g.Retry, func(ctx context.Context) error {
+		return retry.Await(ctx, func() error {
+			var err error
+			issue, err = tracker.GetIssue(key)
+			return err
+		})
+	})
+	return issue, err
+}
+
+// toV1Labels converts git labels to IssueLabel
+func toV1Labels(labels []string) []v1alpha1.IssueLabel {
+	var answer []v1alpha1.IssueLabel
+	for _, label := range labels {
+		answer = append(answer, v1alpha1.IssueLabel{
+			Name: label,
+		})
+	}
+	return answer
+}
+
+// fullCommitMessageText returns the commit message
+func fullCommitMessageText(commit *object.Commit) string {
+	answer := commit.Message
+	fn := func(parent *object.Commit) {
+		text := parent.Message
+		if text != "" {
+			sep := "\n"
+			if strings.HasSuffix(answer, "\n") {
+				sep = ""
+			}
+			answer += sep + text
+		}
+	}
+	fn(commit)
+	return answer
+}
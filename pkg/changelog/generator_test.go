@@ -0,0 +1,131 @@
+package changelog_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/cli"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+	"github.com/shuttlerock/changlog/pkg/config"
+	"github.com/shuttlerock/changlog/pkg/gittest"
+	"github.com/shuttlerock/changlog/pkg/issuefile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateOffline(t *testing.T) {
+	g := cli.NewCLIClient("", nil)
+	dir, shas := gittest.CreateRepository(t, g, gittest.Messages(
+		"chore: initial commit",
+		"feat: add the issues view ABC-1 (#1)",
+		"fix: escape the release notes ABC-2",
+		"fix: use UTF-8 for the release notes",
+	)...)
+
+	tracker, err := issuefile.NewProviderFromFile("issues.yaml", issuefile.File{
+		URL: "https://jira.example.com/browse",
+		Issues: []issuefile.Issue{
+			{Key: "ABC-1", Title: "Issues view", State: "done", Type: "Story", Epic: "ABC-100"},
+			{Key: "ABC-2", Title: "Escaping", Type: "Bug"},
+		},
+	})
+	require.NoError(t, err)
+
+	generate := func() (*changelog.Result, string, string) {
+		// the git provider has the pull request of the commit but it must not be called when offline
+		scmClient, scmData := fake.NewDefault()
+		scmData.PullRequests[1] = &scm.PullRequest{Number: 1, Title: "Add the issues view"}
+
+		generator := &changelog.Generator{
+			Dir:            dir,
+			From:           shas[0],
+			To:             shas[len(shas)-1],
+			Tracker:        tracker,
+			ScmClient:      scmClient,
+			Owner:          "acme",
+			RepositoryName: "changelog",
+			Offline:        true,
+		}
+		result, err := generator.Generate()
+		require.NoError(t, err)
+
+		data, err := yaml.Marshal(result.Release)
+		require.NoError(t, err)
+		renderer := &changelog.MarkdownRenderer{
+			GroupBy: changelog.GroupByType,
+			View:    changelog.ViewCommits,
+		}
+		markdown, err := renderer.Render(result, &giturl.GitRepository{Host: "github.com", Organisation: "acme", Name: "changelog"})
+		require.NoError(t, err)
+		return result, string(data), markdown
+	}
+
+	result, releaseYAML, markdown := generate()
+	_, releaseYAML2, markdown2 := generate()
+	assert.Equal(t, releaseYAML, releaseYAML2, "the Release YAML is the same for the same revisions")
+	assert.Equal(t, markdown, markdown2, "the markdown is the same for the same revisions")
+
+	release := result.Release
+	assert.Equal(t, changelog.EnrichmentUnavailable, release.Annotations[changelog.EnrichmentAnnotation])
+	assert.Equal(t, time.Unix(gittest.FirstCommitTime+3*60, 0).UTC(), release.CreationTimestamp.Time, "the release is created at the time of the to revision")
+	assert.Empty(t, release.Spec.PullRequests, "the pull requests are not looked up")
+	require.Len(t, release.Spec.Commits, 3)
+
+	var issueIDs []string
+	for _, issue := range release.Spec.Issues {
+		issueIDs = append(issueIDs, issue.ID)
+	}
+	assert.Equal(t, []string{"ABC-1", "ABC-2"}, issueIDs, "the issues are looked up in the local issues file")
+	assert.Contains(t, markdown, "[ABC-1](https://jira.example.com/browse/ABC-1)")
+	assert.NotContains(t, markdown, "pull/1")
+}
+
+func TestCommitTeamsByLogin(t *testing.T) {
+	g := cli.NewCLIClient("", nil)
+	dir, shas := gittest.CreateRepository(t, g, gittest.Messages(
+		"chore: initial commit",
+		"fix: pushed without a pull request",
+		"feat: add the issues view (#1)",
+	)...)
+
+	scmClient, scmData := fake.NewDefault()
+	scmData.PullRequests[1] = &scm.PullRequest{
+		Number: 1,
+		Title:  "Add the issues view",
+		Author: scm.User{Login: "jdoe"},
+	}
+	scmData.Commits[shas[2]] = &scm.Commit{
+		Sha: shas[2],
+		Author: scm.Signature{
+			Name:  "Jane Doe",
+			Email: "jane@example.com",
+			Login: "jdoe",
+		},
+	}
+
+	generator := &changelog.Generator{
+		Dir:            dir,
+		From:           shas[0],
+		To:             shas[2],
+		ScmClient:      scmClient,
+		Owner:          "acme",
+		RepositoryName: "changelog",
+		Config: &config.Config{
+			Teams: []config.Team{
+				{Name: "backend", Members: []string{"@jdoe"}},
+			},
+		},
+	}
+	result, err := generator.Generate()
+	require.NoError(t, err)
+
+	// the login of the author is found from the commit of their pull request and applies to all of their commits
+	assert.Equal(t, map[string][]string{
+		shas[1]: {"backend"},
+		shas[2]: {"backend"},
+	}, result.CommitTeams)
+}
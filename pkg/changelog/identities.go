@@ -0,0 +1,39 @@
+package changelog
+
+import (
+	"github.com/shuttlerock/changlog/pkg/users"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+)
+
+// mergeIdentities replaces the users of the commits, issues and pull requests with one canonical record per person
+// combining their details from the git provider, the issue tracker and the git commits
+func mergeIdentities(spec *v1alpha1.ReleaseSpec) {
+	identities := users.NewIdentities()
+	forEachUser(spec, func(u *v1alpha1.UserDetails) *v1alpha1.UserDetails {
+		identities.Add(u)
+		return u
+	})
+	forEachUser(spec, identities.Canonical)
+}
+
+// forEachUser replaces each user of the release with the result of the function
+func forEachUser(spec *v1alpha1.ReleaseSpec, fn func(u *v1alpha1.UserDetails) *v1alpha1.UserDetails) {
+	for k := range spec.Commits {
+		c := &spec.Commits[k]
+		c.Author = fn(c.Author)
+		c.Committer = fn(c.Committer)
+	}
+	for _, summaries := range [][]v1alpha1.IssueSummary{spec.PullRequests, spec.Issues} {
+		for k := range summaries {
+			s := &summaries[k]
+			s.User = fn(s.User)
+			s.ClosedBy = fn(s.ClosedBy)
+			for j := range s.Assignees {
+				u := fn(&s.Assignees[j])
+				if u != nil {
+					s.Assignees[j] = *u
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package changelog
+
+import (
+	"context"
+
+	"github.com/jenkins-x-plugins/jx-changelog/pkg/issues"
+	"github.com/shuttlerock/changlog/pkg/retry"
+	"github.com/shuttlerock/changlog/pkg/tracker"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+)
+
+const (
+	// IssueDetailsAnnotation the annotation of the JSON object of the details of the issues such as their type and
+	// epic indexed by the issue key
+	IssueDetailsAnnotation = AnnotationPrefix + "issue-details"
+)
+
+// addIssueDetails adds the details of the issue such as its type and priority as labels of the issue summary and
+// records them in the result if the issue tracker supports them
+func (g *Generator) addIssueDetails(provider issues.IssueProvider, sha string, summary *v1alpha1.IssueSummary) {
+	detailsProvider, ok := provider.(tracker.DetailsProvider)
+	if !ok {
+		return
+	}
+	var details *tracker.Details
+	err := retry.Do(g.ctx, g.Retry, func(ctx context.Context) error {
+		if contextProvider, ok := provider.(tracker.ContextDetailsProvider); ok {
+			var err error
+			details, err = contextProvider.GetIssueDetailsContext(ctx, summary.ID)
+			return err
+		}
+		return retry.Await(ctx, func() error {
+			var err error
+			details, err = detailsProvider.GetIssueDetails(summary.ID)
+			return err
+		})
+	})
+	if err != nil {
+		g.warnf(DiagnosticIssueDetailsFailed, sha, summary.ID, "Failed to get the details of issue %s from issue tracker %s due to %s", summary.ID, provider.HomeURL(), err)
+		return
+	}
+	if details == nil {
+		return
+	}
+	summary.Labels = append(summary.Labels, toV1Labels(details.Labels())...)
+
+	if g.result.IssueDetails == nil {
+		g.result.IssueDetails = map[string]*tracker.Details{}
+	}
+	g.result.IssueDetails[summary.ID] = details
+}
+
+// addIssueDetailsAnnotation annotates the release with the details of its issues
+func (g *Generator) addIssueDetailsAnnotation() error {
+	if len(g.result.IssueDetails) == 0 {
+		return nil
+	}
+	return setJSONAnnotation(g.result.Release, IssueDetailsAnnotation, g.result.IssueDetails)
+}
+
+// commitIssueDetails returns the details of the first issue of the commit which has any
+func (r *Result) commitIssueDetails(commit *v1alpha1.CommitSummary) *tracker.Details {
+	if r == nil {
+		return nil
+	}
+	for _, id := range commit.IssueIDs {
+		if details := r.IssueDetails[id]; details != nil {
+			return details
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package changelog
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+)
+
+const (
+	// ViewCommits renders the changelog as a list of commits
+	ViewCommits = "commits"
+	// ViewIssues renders the changelog as a list of issues and pull requests with their commits nested underneath
+	ViewIssues = "issues"
+)
+
+// ViewValues the supported views of the changelog
+var ViewValues = []string{ViewCommits, ViewIssues}
+
+// epicGroup the issues and pull requests of an epic
+type epicGroup struct {
+	key   string
+	epic  *v1alpha1.IssueSummary
+	items []*v1alpha1.IssueSummary
+}
+
+// issueCommit a commit listed under an issue or pull request
+type issueCommit struct {
+	commit *v1alpha1.CommitSummary
+	info   *CommitInfo
+}
+
+// issueCommits returns the commits referencing each issue or pull request indexed by its ID, leaving out the commits
+// skipped by their labels or release notes, along with the IDs of the issues and pull requests all of whose commits
+// were left out
+func (r *MarkdownRenderer) issueCommits(commits []v1alpha1.CommitSummary, prMap map[string]*v1alpha1.IssueSummary) (map[string][]issueCommit, map[string]bool) {
+	answer := map[string][]issueCommit{}
+	skipped := map[string]bool{}
+	for k := range commits {
+		commit := &commits[k]
+		ci, ok := r.listedCommit(commit, prMap)
+		for _, id := range commit.IssueIDs {
+			if !ok {
+				if len(answer[id]) == 0 {
+					skipped[id] = true
+				}
+				continue
+			}
+			delete(skipped, id)
+			answer[id] = append(answer[id], issueCommit{commit: commit, info: ci})
+		}
+	}
+	return answer, skipped
+}
+
+// renderIssues renders the changelog with each issue and pull request once along with its status and the commits
+// referencing it, grouped by epic where known. The commits without any issue or pull request are listed last
+func (r *MarkdownRenderer) renderIssues(result *Result, gitInfo *giturl.GitRepository) string {
+	spec := &result.Release.Spec
+	issueMap, prMap := issueMaps(spec)
+	issueCommits, skipped := r.issueCommits(spec.Commits, prMap)
+
+	var items []*v1alpha1.IssueSummary
+	for k := range spec.Issues {
+		issue := &spec.Issues[k]
+		if !skipped[issue.ID] {
+			items = append(items, issue)
+		}
+	}
+	for k := range spec.PullRequests {
+		pr := &spec.PullRequests[k]
+		if skipped[pr.ID] || r.isSkippedByLabel(issueLabelNames(pr.Labels)) {
+			continue
+		}
+		note, found := ExtractReleaseNote(pr.Body)
+		if found && IsReleaseNoteNone(note) {
+			continue
+		}
+		items = append(items, pr)
+	}
+
+	groups := map[string]*epicGroup{}
+	var epicKeys []string
+	other := &epicGroup{}
+	for _, item := range items {
+		key := result.IssueDetails[item.ID].EpicKey()
+		if key == "" || key == item.ID {
+			continue
+		}
+		if groups[key] == nil {
+			groups[key] = &epicGroup{key: key, epic: issueMap[key]}
+			epicKeys = append(epicKeys, key)
+		}
+	}
+	sort.Strings(epicKeys)
+	for _, item := range items {
+		if groups[item.ID] != nil {
+			// the epic is the heading of its own group
+			continue
+		}
+		group := groups[result.IssueDetails[item.ID].EpicKey()]
+		if group == nil {
+			group = other
+		}
+		group.items = append(group.items, item)
+	}
+
+	var remaining []string
+	for k := range spec.Commits {
+		commit := &spec.Commits[k]
+		if len(commit.IssueIDs) > 0 || r.dependencyCommits[commit.SHA] != "" {
+			continue
+		}
+		ci, ok := r.listedCommit(commit, prMap)
+		if !ok {
+			continue
+		}
+		remaining = append(remaining, "* "+r.describeIssueCommit(result, gitInfo, commit, ci, "  "))
+	}
+
+	if len(items) == 0 && len(remaining) == 0 {
+		return ""
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("## Changes\n")
+	for _, key := range epicKeys {
+		group := groups[key]
+		buffer.WriteString("\n### " + describeEpic(group, result) + "\n\n")
+		if group.epic != nil {
+			for _, c := range issueCommits[key] {
+				buffer.WriteString("* " + r.describeIssueCommit(result, gitInfo, c.commit, c.info, "  "))
+			}
+		}
+		r.writeIssueItems(&buffer, result, gitInfo, group.items, issueCommits)
+	}
+	if len(other.items) > 0 {
+		buffer.WriteString("\n")
+		if len(epicKeys) > 0 {
+			buffer.WriteString("### Other Issues\n\n")
+		}
+		r.writeIssueItems(&buffer, result, gitInfo, other.items, issueCommits)
+	}
+	if len(remaining) > 0 {
+		buffer.WriteString("\n")
+		if len(items) > 0 {
+			buffer.WriteString("### Other Changes\n\n")
+		}
+		for _, line := range remaining {
+			buffer.WriteString(line)
+		}
+	}
+	return buffer.String()
+}
+
+// writeIssueItems writes each issue or pull request with its status and the commits referencing it
+func (r *MarkdownRenderer) writeIssueItems(buffer *bytes.Buffer, result *Result, gitInfo *giturl.GitRepository, items []*v1alpha1.IssueSummary, issueCommits map[string][]issueCommit) {
+	for _, item := range items {
+		buffer.WriteString("* " + describeIssue(gitInfo, item) + describeIssueState(result, item) + "\n")
+		for _, c := range issueCommits[item.ID] {
+			buffer.WriteString("  * " + r.describeIssueCommit(result, gitInfo, c.commit, c.info, "    "))
+		}
+	}
+}
+
+// describeIssueCommit describes the commit without its issues, as it is nested under them, along with the chart
+// dependency it is from if any, followed by the commits of the branch it merged at the given indent
+func (r *MarkdownRenderer) describeIssueCommit(result *Result, gitInfo *giturl.GitRepository, commit *v1alpha1.CommitSummary, ci *CommitInfo, indent string) string {
+	answer := describeCommit(gitInfo, commit, ci, nil)
+	if name := r.dependencyCommits[commit.SHA]; name != "" {
+		answer += " (" + name + ")"
+	}
+	answer += "\n"
+	for k := range result.NestedCommits[commit.SHA] {
+		nested := &result.NestedCommits[commit.SHA][k]
+		answer += indent + "* " + describeCommit(gitInfo, nested, ParseCommit(nested.Message), nil) + "\n"
+	}
+	return answer
+}
+
+// describeIssueState returns the status of the issue or pull request in brackets if known, falling back to the
+// status in the issue tracker details
+func describeIssueState(result *Result, issue *v1alpha1.IssueSummary) string {
+	state := issue.State
+	if details := result.IssueDetails[issue.ID]; state == "" && details != nil {
+		state = details.Status
+	}
+	if state == "" {
+		return ""
+	}
+	return " (" + state + ")"
+}
+
+// describeEpic returns the heading of the epic linking to it if it is in the release or else if the URL of one of
+// its issues shows where it is
+func describeEpic(group *epicGroup, result *Result) string {
+	if group.epic != nil {
+		return strings.TrimSpace(describeIssueShort(group.epic)+group.epic.Title) + describeIssueState(result, group.epic)
+	}
+	title := group.key
+	url := ""
+	for _, item := range group.items {
+		details := result.IssueDetails[item.ID]
+		if details != nil && details.EpicName != "" && title == group.key {
+			title = group.key + " " + details.EpicName
+		}
+		if url == "" && strings.HasSuffix(item.URL, "/"+item.ID) {
+			url = strings.TrimSuffix(item.URL, item.ID) + group.key
+		}
+	}
+	if url == "" {
+		return "Epic " + title
+	}
+	return "Epic [" + group.key + "](" + url + ")" + strings.TrimPrefix(title, group.key)
+}
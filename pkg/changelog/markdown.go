@@ -0,0 +1,448 @@
+package changelog
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/shuttlerock/changlog/pkg/config"
+	"github.com/shuttlerock/changlog/pkg/tracker"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+)
+
+const (
+	// GroupByType groups the changelog by the conventional commit type
+	GroupByType = "type"
+	// GroupByLabels groups the changelog by the labels of the pull requests
+	GroupByLabels = "labels"
+	// GroupByTeam groups the changelog by the teams of the commits
+	GroupByTeam = "team"
+	// GroupByIssueType groups the changelog by the type of the issues of the commits such as Bug
+	GroupByIssueType = "issue-type"
+)
+
+// GroupByValues the supported ways of grouping the changelog
+var GroupByValues = []string{GroupByType, GroupByLabels, GroupByTeam, GroupByIssueType}
+
+// MarkdownRenderer renders the changelog markdown of a Result
+type MarkdownRenderer struct {
+	// GroupBy how to group the changelog entries, one of GroupByValues
+	GroupBy string
+
+	// View whether to list the commits or the issues and pull requests with their commits, one of ViewValues
+	View string
+
+	// Config the changelog configuration of the label groups and skipped labels
+	Config *config.Config
+
+	labelGroups       map[int]*CommitGroup
+	teamGroups        map[string]*CommitGroup
+	issueTypeGroups   map[int]*CommitGroup
+	dependencyCommits map[string]string
+}
+
+// Render generates the markdown document for the commits of the result
+func (r *MarkdownRenderer) Render(result *Result, gitInfo *giturl.GitRepository) (string, error) {
+	releaseSpec := &result.Release.Spec
+	r.dependencyCommits = result.dependencyCommits()
+	if r.View == ViewIssues {
+		changes := r.renderIssues(result, gitInfo)
+		if changes == "" && len(result.DependencyUpdates) == 0 {
+			return "", nil
+		}
+		var buffer bytes.Buffer
+		buffer.WriteString(changes)
+		writeDependencyUpdates(&buffer, result.DependencyUpdates)
+		r.writeDependencyChanges(&buffer, result, gitInfo, true)
+		writeContributors(&buffer, result, gitInfo)
+		return buffer.String(), nil
+	}
+	var commitInfos []*CommitInfo
+
+	groupAndCommits := map[int]*GroupAndCommitInfos{}
+
+	issues := releaseSpec.Issues
+	prs := releaseSpec.PullRequests
+	issueMap, prMap := issueMaps(releaseSpec)
+
+	if r.GroupBy == GroupByTeam {
+		r.createTeamGroups(result.CommitTeams)
+	}
+
+	for _, cs := range releaseSpec.Commits {
+		commit := cs
+		if r.dependencyCommits[commit.SHA] != "" {
+			continue
+		}
+		ci, ok := r.listedCommit(&commit, prMap)
+		if !ok {
+			continue
+		}
+		if r.GroupBy == GroupByLabels {
+			ci.group = r.labelGroup(commitLabels(&commit, prMap))
+		}
+		if r.GroupBy == GroupByIssueType {
+			ci.group = r.issueTypeGroup(result.commitIssueDetails(&commit))
+		}
+
+		description := "* " + describeCommit(gitInfo, &commit, ci, issueMap) + "\n"
+		for _, nested := range result.NestedCommits[commit.SHA] {
+			nci := ParseCommit(nested.Message)
+			description += "  * " + describeCommit(gitInfo, &nested, nci, issueMap) + "\n"
+		}
+		groups := []*CommitGroup{ci.Group()}
+		if r.GroupBy == GroupByTeam {
+			groups = r.commitTeamGroups(result.CommitTeams[commit.SHA])
+		}
+		for _, group := range groups {
+			gac := groupAndCommits[group.Order]
+			if gac == nil {
+				gac = &GroupAndCommitInfos{
+					group:   group,
+					commits: []string{},
+				}
+				groupAndCommits[group.Order] = gac
+			}
+			gac.commits = append(gac.commits, description)
+		}
+		commitInfos = append(commitInfos, ci)
+	}
+
+	var buffer bytes.Buffer
+	if len(commitInfos) == 0 && len(issues) == 0 && len(prs) == 0 && len(result.DependencyUpdates) == 0 {
+		return "", nil
+	}
+
+	buffer.WriteString("## Changes\n")
+
+	var orders []int
+	for order := range groupAndCommits {
+		orders = append(orders, order)
+	}
+	sort.Ints(orders)
+
+	hasTitle := false
+	for _, order := range orders {
+		gac := groupAndCommits[order]
+		if len(gac.commits) == 0 {
+			continue
+		}
+		group := gac.group
+		buffer.WriteString("\n")
+		title := group.Title
+		legend := ""
+		if title == "" && hasTitle {
+			title = "Other Changes"
+			if r.GroupBy == GroupByType {
+				legend = "These commits did not use [Conventional Commits](https://conventionalcommits.org/) formatted messages:\n\n"
+			}
+		}
+		if title != "" {
+			hasTitle = true
+			buffer.WriteString("### " + title + "\n\n" + legend)
+		}
+		previous := ""
+		for _, msg := range gac.commits {
+			if msg != previous {
+				buffer.WriteString(msg)
+				previous = msg
+			}
+		}
+	}
+
+	writeDependencyUpdates(&buffer, result.DependencyUpdates)
+	r.writeDependencyChanges(&buffer, result, gitInfo, false)
+
+	if len(issues) > 0 {
+		buffer.WriteString("\n### Issues\n\n")
+
+		previous := ""
+		for k := range issues {
+			msg := describeIssue(gitInfo, &issues[k])
+			if msg != previous {
+				buffer.WriteString("* " + msg + "\n")
+				previous = msg
+			}
+		}
+	}
+	if len(prs) > 0 {
+		buffer.WriteString("\n### Pull Requests\n\n")
+
+		previous := ""
+		for k := range prs {
+			if r.isSkippedByLabel(issueLabelNames(prs[k].Labels)) {
+				continue
+			}
+			note, found := ExtractReleaseNote(prs[k].Body)
+			if found && IsReleaseNoteNone(note) {
+				continue
+			}
+			msg := describePullRequest(gitInfo, &prs[k], note)
+			if msg != previous {
+				buffer.WriteString("* " + msg + "\n")
+				previous = msg
+			}
+		}
+	}
+	writeContributors(&buffer, result, gitInfo)
+	return buffer.String(), nil
+}
+
+// writeDependencyUpdates writes the table of the chart dependencies whose version changed
+func writeDependencyUpdates(buffer *bytes.Buffer, updates []DependencyUpdate) {
+	if len(updates) == 0 {
+		return
+	}
+	buffer.WriteString("\n### Dependency Updates\n\n")
+	buffer.WriteString("| Dependency | From | To |\n")
+	buffer.WriteString("| --- | --- | --- |\n")
+	for _, u := range updates {
+		buffer.WriteString("| " + u.Name + " | " + u.FromVersion + " | " + u.ToVersion + " |\n")
+	}
+}
+
+// writeDependencyChanges writes the commits nested from each chart dependency under its own heading so that they
+// are not mixed up with the changes of the umbrella chart. The commits referencing issues are left out when they are
+// already listed under their issues
+func (r *MarkdownRenderer) writeDependencyChanges(buffer *bytes.Buffer, result *Result, gitInfo *giturl.GitRepository, underIssues bool) {
+	issueMap, prMap := issueMaps(&result.Release.Spec)
+	commits := result.Release.Spec.Commits
+	for _, u := range result.DependencyUpdates {
+		var lines []string
+		for k := range commits {
+			commit := &commits[k]
+			if r.dependencyCommits[commit.SHA] != u.Name || (underIssues && len(commit.IssueIDs) > 0) {
+				continue
+			}
+			ci, ok := r.listedCommit(commit, prMap)
+			if !ok {
+				continue
+			}
+			lines = append(lines, "* "+describeCommit(gitInfo, commit, ci, issueMap)+"\n")
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		buffer.WriteString("\n### " + u.Name + " " + u.FromVersion + " to " + u.ToVersion + "\n\n")
+		for _, line := range lines {
+			buffer.WriteString(line)
+		}
+	}
+}
+
+// issueMaps returns the issues and pull requests indexed by ID along with the pull requests alone
+func issueMaps(spec *v1alpha1.ReleaseSpec) (map[string]*v1alpha1.IssueSummary, map[string]*v1alpha1.IssueSummary) {
+	issueMap := map[string]*v1alpha1.IssueSummary{}
+	prMap := map[string]*v1alpha1.IssueSummary{}
+	for k := range spec.Issues {
+		issueMap[spec.Issues[k].ID] = &spec.Issues[k]
+	}
+	for k := range spec.PullRequests {
+		prMap[spec.PullRequests[k].ID] = &spec.PullRequests[k]
+		issueMap[spec.PullRequests[k].ID] = &spec.PullRequests[k]
+	}
+	return issueMap, prMap
+}
+
+// writeContributors writes the contributors section
+func writeContributors(buffer *bytes.Buffer, result *Result, gitInfo *giturl.GitRepository) {
+	if len(result.Contributors) == 0 {
+		return
+	}
+	buffer.WriteString("\n### Contributors\n\n")
+	for k := range result.Contributors {
+		buffer.WriteString("* " + describeContributor(gitInfo, &result.Contributors[k]) + "\n")
+	}
+}
+
+// commitLabels returns the labels of the pull requests the commit belongs to
+func commitLabels(cs *v1alpha1.CommitSummary, prMap map[string]*v1alpha1.IssueSummary) []string {
+	var answer []string
+	for _, id := range cs.IssueIDs {
+		pr := prMap[id]
+		if pr != nil {
+			answer = append(answer, issueLabelNames(pr.Labels)...)
+		}
+	}
+	return answer
+}
+
+func issueLabelNames(labels []v1alpha1.IssueLabel) []string {
+	var answer []string
+	for _, label := range labels {
+		answer = append(answer, label.Name)
+	}
+	return answer
+}
+
+// listedCommit parses the commit unless it is left out of the changelog by the labels of its pull requests or a
+// release note of none, using the release note in place of the subject if there is one
+func (r *MarkdownRenderer) listedCommit(commit *v1alpha1.CommitSummary, prMap map[string]*v1alpha1.IssueSummary) (*CommitInfo, bool) {
+	if commit.Message == "" || r.isSkippedByLabel(commitLabels(commit, prMap)) {
+		return nil, false
+	}
+	ci := ParseCommit(commit.Message)
+	note, found := commitReleaseNote(commit, prMap)
+	if found {
+		if IsReleaseNoteNone(note) {
+			return nil, false
+		}
+		ci.ReleaseNote = note
+	}
+	return ci, true
+}
+
+// isSkippedByLabel returns true if any of the labels should drop the entry from the changelog
+func (r *MarkdownRenderer) isSkippedByLabel(labels []string) bool {
+	return r.Config != nil && ContainsAnyLabel(r.Config.SkipLabels, labels)
+}
+
+// labelGroup returns the highest priority configured group matching any of the labels or the
+// group for other changes if there is no match
+func (r *MarkdownRenderer) labelGroup(labels []string) *CommitGroup {
+	var labelGroups []config.LabelGroup
+	if r.Config != nil {
+		labelGroups = r.Config.LabelGroups
+	}
+	index := -1
+	for i, lg := range labelGroups {
+		if index >= 0 && lg.Priority <= labelGroups[index].Priority {
+			continue
+		}
+		if ContainsAnyLabel(lg.Labels, labels) {
+			index = i
+		}
+	}
+
+	if r.labelGroups == nil {
+		r.labelGroups = map[int]*CommitGroup{}
+	}
+	group := r.labelGroups[index]
+	if group == nil {
+		group = &CommitGroup{
+			Order: len(labelGroups),
+		}
+		if index >= 0 {
+			group.Title = labelGroups[index].Title
+			group.Order = index
+		}
+		r.labelGroups[index] = group
+	}
+	return group
+}
+
+// issueTypeGroup returns the configured group of the issue type or the group for other changes if the commit has no
+// issue or its type is not in any group
+func (r *MarkdownRenderer) issueTypeGroup(details *tracker.Details) *CommitGroup {
+	typeGroups := config.DefaultIssueTypeGroups
+	if r.Config != nil && len(r.Config.IssueTypeGroups) > 0 {
+		typeGroups = r.Config.IssueTypeGroups
+	}
+	index := -1
+	for i, tg := range typeGroups {
+		if details.IsType(tg.Types...) {
+			index = i
+			break
+		}
+	}
+
+	if r.issueTypeGroups == nil {
+		r.issueTypeGroups = map[int]*CommitGroup{}
+	}
+	group := r.issueTypeGroups[index]
+	if group == nil {
+		group = &CommitGroup{
+			Order: len(typeGroups),
+		}
+		if index >= 0 {
+			group.Title = typeGroups[index].Title
+			group.Order = index
+		}
+		r.issueTypeGroups[index] = group
+	}
+	return group
+}
+
+// createTeamGroups creates a group per team ordered by name followed by the group of commits without a team
+func (r *MarkdownRenderer) createTeamGroups(commitTeams map[string][]string) {
+	var names []string
+	r.teamGroups = map[string]*CommitGroup{}
+	for _, teams := range commitTeams {
+		for _, team := range teams {
+			if r.teamGroups[team] == nil {
+				r.teamGroups[team] = &CommitGroup{Title: team}
+				names = append(names, team)
+			}
+		}
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		r.teamGroups[name].Order = i
+	}
+	r.teamGroups[""] = &CommitGroup{Order: len(names)}
+}
+
+// commitTeamGroups returns the groups of the teams of a commit or the group of commits without a team
+func (r *MarkdownRenderer) commitTeamGroups(teams []string) []*CommitGroup {
+	if len(teams) == 0 {
+		return []*CommitGroup{r.teamGroups[""]}
+	}
+	var answer []*CommitGroup
+	for _, team := range teams {
+		answer = append(answer, r.teamGroups[team])
+	}
+	return answer
+}
+
+// ContainsAnyLabel returns true if any of the labels is in the list ignoring case
+func ContainsAnyLabel(list, labels []string) bool {
+	for _, l := range list {
+		for _, label := range labels {
+			if strings.EqualFold(l, label) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// describeContributor describes the contributor with their number of commits and whether it is their first
+// contribution
+func describeContributor(info *giturl.GitRepository, c *Contributor) string {
+	label := contributorLabel(c)
+	url := c.URL
+	if url == "" && c.Login != "" {
+		url = stringhelpers.UrlJoin(info.HostURL(), c.Login)
+	}
+	answer := label
+	if url != "" {
+		answer = "[" + label + "](" + url + ")"
+	}
+	if c.Login != "" && c.Name != "" && c.Name != c.Login {
+		answer += " " + c.Name
+	}
+	if c.Commits == 1 {
+		answer += " - 1 commit"
+	} else {
+		answer += " - " + strconv.Itoa(c.Commits) + " commits"
+	}
+	if c.FirstTime {
+		answer += " (first contribution)"
+	}
+	return answer
+}
+
+func describeIssue(info *giturl.GitRepository, issue *v1alpha1.IssueSummary) string {
+	return describeIssueShort(issue) + issue.Title + describeUser(info, issue.User)
+}
+
+// describePullRequest describes the pull request using its release note in place of its title if it has one
+func describePullRequest(info *giturl.GitRepository, pr *v1alpha1.IssueSummary, note string) string {
+	if note == "" {
+		return describeIssue(info, pr)
+	}
+	return describeIssueShort(pr) + strings.Join(strings.Split(note, "\n"), "\n  ") + describeUser(info, pr.User)
+}
@@ -0,0 +1,70 @@
+package changelog_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderCommitsWithoutTitledType(t *testing.T) {
+	release := changelog.NewRelease(time.Unix(0, 0))
+	for _, message := range []string{
+		"feat: add the issues view",
+		"ci: run the tests on pull requests",
+		"build: bump the go version",
+		"unknown: something else",
+		"Fix foo: bar",
+	} {
+		release.Spec.Commits = append(release.Spec.Commits, v1alpha1.CommitSummary{Message: message})
+	}
+
+	renderer := &changelog.MarkdownRenderer{GroupBy: changelog.GroupByType}
+	markdown, err := renderer.Render(&changelog.Result{Release: release}, &giturl.GitRepository{})
+	require.NoError(t, err)
+
+	expected := `## Changes
+
+### New Features
+
+* add the issues view
+
+### Other Changes
+
+These commits did not use [Conventional Commits](https://conventionalcommits.org/) formatted messages:
+
+* run the tests on pull requests
+* bump the go version
+* something else
+* Fix foo: bar
+`
+	assert.Equal(t, expected, markdown)
+}
+
+func TestParseCommitWithoutType(t *testing.T) {
+	testCases := []struct {
+		message string
+		kind    string
+		feature string
+		text    string
+	}{
+		{message: "feat(ui): add the issues view", kind: "feat", feature: "ui", text: "add the issues view"},
+		{message: "ci: run the tests", kind: "ci", text: "run the tests"},
+		{message: "Fix foo: bar", text: "Fix foo: bar"},
+		{message: "Fix the (ui) view: bar", text: "Fix the (ui) view: bar"},
+		{message: "no type at all", text: "no type at all"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			ci := changelog.ParseCommit(tc.message)
+			assert.Equal(t, tc.kind, ci.Kind)
+			assert.Equal(t, tc.feature, ci.Feature)
+			assert.Equal(t, tc.text, ci.Message)
+			require.NotNil(t, ci.Group())
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package changelog
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// commitChangesPaths returns true if the commit changes a file in any of the paths compared to its first parent
+func commitChangesPaths(commit *object.Commit, paths []string) (bool, error) {
+	changed, err := commitChangedFiles(commit)
+	if err != nil {
+		return false, err
+	}
+	for _, name := range changed {
+		if pathMatches(name, paths) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// commitChangedFiles returns the files added, changed, deleted or renamed by the commit compared to its first parent
+func commitChangedFiles(commit *object.Commit) ([]string, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the tree of commit %s", commit.Hash.String())
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find the parent of commit %s", commit.Hash.String())
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find the tree of commit %s", parent.Hash.String())
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the changes of commit %s", commit.Hash.String())
+	}
+	var answer []string
+	for _, change := range changes {
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if name != "" && (len(answer) == 0 || answer[len(answer)-1] != name) {
+				answer = append(answer, name)
+			}
+		}
+	}
+	return answer, nil
+}
+
+// pathMatches returns true if the file is one of the paths or inside one of them
+func pathMatches(file string, paths []string) bool {
+	for _, p := range paths {
+		p = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(p)), "/")
+		if p == "." || file == p || strings.HasPrefix(file, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// changesPaths returns true if the commit changes the paths the release is being generated for
+func (g *Generator) changesPaths(commit *object.Commit) bool {
+	if len(g.Paths) == 0 {
+		return true
+	}
+	changed, err := commitChangesPaths(commit, g.Paths)
+	if err != nil {
+		log.Logger().Warnf("failed to find the files changed by commit %s: %v", commit.Hash.String(), err)
+		return true
+	}
+	if !changed {
+		g.recordExcludedCommit(commit, "outside the source paths "+strings.Join(g.Paths, ", "))
+	}
+	return changed
+}
@@ -0,0 +1,288 @@
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/kube"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/scmhelpers"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/retry"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+var (
+	// SquashMergeRegex matches the `(#123)` suffix added to the subject of squash and rebase merged commits
+	SquashMergeRegex = regexp.MustCompile(`\(#(\d+)\)\s*$`)
+	// MergeCommitRegex matches the subject of a merge commit created by a pull request
+	MergeCommitRegex = regexp.MustCompile(`^Merge (?:pull|merge) request #(\d+)\b`)
+)
+
+// pullRequestNumbersFromMessage returns the pull request numbers referenced by the subject of a squash, rebase or
+// merge commit message
+func pullRequestNumbersFromMessage(message string) []int {
+	subject := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+
+	var answer []int
+	for _, regex := range []*regexp.Regexp{SquashMergeRegex, MergeCommitRegex} {
+		match := regex.FindStringSubmatch(subject)
+		if len(match) < 2 {
+			continue
+		}
+		n, err := strconv.Atoi(match[1])
+		if err == nil {
+			answer = appendPullRequestNumber(answer, n)
+		}
+	}
+	return answer
+}
+
+func appendPullRequestNumber(numbers []int, n int) []int {
+	for _, existing := range numbers {
+		if existing == n {
+			return numbers
+		}
+	}
+	return append(numbers, n)
+}
+
+// findPullRequestNumbers returns the numbers of the pull requests which contain the given commit
+func (g *Generator) findPullRequestNumbers(commit *object.Commit) []int {
+	answer := pullRequestNumbersFromMessage(commit.Message)
+
+	numbers, err := g.queryPullRequestNumbers(commit.Hash.String())
+	if err != nil {
+		log.Logger().Debugf("failed to query pull requests for commit %s: %v", commit.Hash.String(), err)
+	}
+	for _, n := range numbers {
+		answer = appendPullRequestNumber(answer, n)
+	}
+	return answer
+}
+
+// queryPullRequestNumbers asks the git provider for the pull requests associated with the commit sha.
+// Only GitHub exposes this so other providers rely on the commit message
+func (g *Generator) queryPullRequestNumbers(sha string) ([]int, error) {
+	scmClient := g.ScmClient
+	if scmClient == nil || scmClient.Driver != scm.DriverGithub {
+		return nil, nil
+	}
+	fullName := scm.Join(g.Owner, g.RepositoryName)
+
+	var pullRequests []struct {
+		Number int `json:"number"`
+	}
+	err := retry.Do(g.ctx, g.Retry, func(ctx context.Context) error {
+		res, err := scmClient.Do(ctx, &scm.Request{
+			Method: http.MethodGet,
+			Path:   fmt.Sprintf("repos/%s/commits/%s/pulls", fullName, sha),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to list pull requests for commit %s in repository %s", sha, fullName)
+		}
+		defer res.Body.Close()
+		if res.Status >= http.StatusMultipleChoices {
+			err = errors.Errorf("failed to list pull requests for commit %s in repository %s: status %d", sha, fullName, res.Status)
+			return retry.ScmError(res, err)
+		}
+		err = json.NewDecoder(res.Body).Decode(&pullRequests)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse pull requests for commit %s", sha)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var answer []int
+	for _, pr := range pullRequests {
+		answer = append(answer, pr.Number)
+	}
+	return answer, nil
+}
+
+// addPullRequests finds the pull requests which contain the commit and adds them to the release.
+// The commit summary may be nil for commits which are not listed in the release such as merge commits
+func (g *Generator) addPullRequests(spec *v1alpha1.ReleaseSpec, commit *v1alpha1.CommitSummary, rawCommit *object.Commit) {
+	scmClient := g.ScmClient
+	if scmClient == nil || scmClient.PullRequests == nil || g.RepositoryName == "" {
+		return
+	}
+	g.addPullRequestNumbers(spec, commit, g.findPullRequestNumbers(rawCommit))
+}
+
+// addPullRequestNumbers adds the pull requests with the given numbers to the release and links them to the commit
+func (g *Generator) addPullRequestNumbers(spec *v1alpha1.ReleaseSpec, commit *v1alpha1.CommitSummary, numbers []int) {
+	scmClient := g.ScmClient
+	if scmClient == nil || scmClient.PullRequests == nil || g.RepositoryName == "" {
+		return
+	}
+	fullName := scm.Join(g.Owner, g.RepositoryName)
+	sha := ""
+	if commit != nil {
+		sha = commit.SHA
+	}
+
+	for _, n := range numbers {
+		id := strconv.Itoa(n)
+		if commit != nil && stringhelpers.StringArrayIndex(commit.IssueIDs, id) >= 0 {
+			continue
+		}
+		if !g.foundPullRequests[n] {
+			g.foundPullRequests[n] = true
+
+			var pr *scm.PullRequest
+			err := retry.Do(g.ctx, g.Retry, func(ctx context.Context) error {
+				var res *scm.Response
+				var err error
+				pr, res, err = scmClient.PullRequests.Find(ctx, fullName, n)
+				if scmhelpers.IsScmNotFound(err) {
+					return retry.Permanent(err)
+				}
+				return retry.ScmError(res, err)
+			})
+			if err != nil {
+				if !scmhelpers.IsScmNotFound(err) {
+					g.warnf(DiagnosticPullRequestLookupFailed, sha, id, "Failed to lookup pull request %d in repository %s due to %s", n, fullName, err)
+				}
+				continue
+			}
+			if pr == nil {
+				g.warnf(DiagnosticPullRequestNotFound, sha, id, "Failed to find pull request %d for repository %s", n, fullName)
+				continue
+			}
+			summary := g.toPullRequestSummary(pr, sha)
+			g.updatePullRequestAuthor(&summary, pr, commit)
+			spec.PullRequests = append(spec.PullRequests, summary)
+		}
+		if commit != nil && findIssueSummary(spec.PullRequests, id) != nil {
+			commit.IssueIDs = append(commit.IssueIDs, id)
+		}
+	}
+}
+
+// toPullRequestSummary converts a pull request into an IssueSummary
+func (g *Generator) toPullRequestSummary(pr *scm.PullRequest, sha string) v1alpha1.IssueSummary {
+	resolver := g.Resolver
+	user, err := resolver.Resolve(&pr.Author)
+	if err != nil {
+		g.warnf(DiagnosticUserNotResolved, sha, strconv.Itoa(pr.Number), "Failed to resolve user %v for pull request %d", pr.Author, pr.Number)
+	}
+	if user == nil && pr.Author.Login != "" {
+		user = resolver.GitUserToUser(&pr.Author)
+		user.URL = pr.Author.Link
+		user.AvatarURL = pr.Author.Avatar
+	}
+
+	var labels []string
+	for _, label := range pr.Labels {
+		if label != nil {
+			labels = append(labels, label.Name)
+		}
+	}
+
+	state := pr.State
+	if pr.Merged {
+		state = "merged"
+	}
+	return v1alpha1.IssueSummary{
+		ID:                strconv.Itoa(pr.Number),
+		URL:               pr.Link,
+		Title:             pr.Title,
+		Body:              pr.Body,
+		State:             state,
+		User:              user,
+		CreationTimestamp: kube.ToMetaTime(&pr.Created),
+		Labels:            toV1Labels(labels),
+	}
+}
+
+// updatePullRequestAuthor takes over the email of the pull request author from the commit on the git provider if
+// they authored it as the git provider does not usually return the email of users. The login of the commit author
+// is also recorded as the git commits only have the name and email of their authors
+func (g *Generator) updatePullRequestAuthor(summary *v1alpha1.IssueSummary, pr *scm.PullRequest, commit *v1alpha1.CommitSummary) {
+	if commit == nil || commit.SHA == "" {
+		return
+	}
+	found := g.findProviderCommit(commit.SHA)
+	if found == nil {
+		return
+	}
+	author := found.Author
+	if author.Login != "" && author.Email != "" {
+		g.commitLogins[strings.ToLower(author.Email)] = author.Login
+	}
+	if summary.User == nil || summary.User.Email != "" {
+		return
+	}
+	_, err := g.Resolver.UpdateUserFromPRAuthor(summary.User, pr, []*scm.Commit{found})
+	if err != nil {
+		g.warnf(DiagnosticUserNotResolved, commit.SHA, summary.ID, "Failed to update the author of pull request %s: %v", summary.ID, err)
+	}
+}
+
+// findProviderCommit returns the commit on the git provider which includes the login of its author or nil if it
+// cannot be found
+func (g *Generator) findProviderCommit(sha string) *scm.Commit {
+	scmClient := g.ScmClient
+	if scmClient == nil || scmClient.Git == nil || g.RepositoryName == "" {
+		return nil
+	}
+	fullName := scm.Join(g.Owner, g.RepositoryName)
+	var commit *scm.Commit
+	err := retry.Do(g.ctx, g.Retry, func(ctx context.Context) error {
+		var res *scm.Response
+		var err error
+		commit, res, err = scmClient.Git.FindCommit(ctx, fullName, sha)
+		if scmhelpers.IsScmNotFound(err) {
+			return retry.Permanent(err)
+		}
+		return retry.ScmError(res, err)
+	})
+	if err != nil {
+		log.Logger().Debugf("failed to find commit %s in repository %s: %v", sha, fullName, err)
+		return nil
+	}
+	return commit
+}
+
+// addCommitLogins sets the login of the commit authors from the git provider commits of their pull requests matching
+// them by email, so that the commit authors can be matched with the pull request authors and the team members
+func (g *Generator) addCommitLogins(spec *v1alpha1.ReleaseSpec) {
+	if len(g.commitLogins) == 0 {
+		return
+	}
+	addLogin := func(commit *v1alpha1.CommitSummary) {
+		if commit.Author == nil || commit.Author.Login != "" || commit.Author.Email == "" {
+			return
+		}
+		commit.Author.Login = g.commitLogins[strings.ToLower(commit.Author.Email)]
+	}
+	for k := range spec.Commits {
+		addLogin(&spec.Commits[k])
+	}
+	for _, nested := range g.result.NestedCommits {
+		for k := range nested {
+			addLogin(&nested[k])
+		}
+	}
+}
+
+// findIssueSummary returns the summary with the given id or nil if it cannot be found
+func findIssueSummary(summaries []v1alpha1.IssueSummary, id string) *v1alpha1.IssueSummary {
+	for k := range summaries {
+		if summaries[k].ID == id {
+			return &summaries[k]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package changelog_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/cli"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+	"github.com/shuttlerock/changlog/pkg/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestAuthorEmailFromCommit(t *testing.T) {
+	g := cli.NewCLIClient("", nil)
+	dir, shas := gittest.CreateRepository(t, g, gittest.Messages(
+		"chore: initial commit",
+		"feat: add the issues view (#1)",
+	)...)
+
+	scmClient, scmData := fake.NewDefault()
+	// the git provider returns the login of the pull request author but not their email
+	scmData.PullRequests[1] = &scm.PullRequest{
+		Number: 1,
+		Title:  "Add the issues view",
+		Author: scm.User{Login: "jdoe"},
+	}
+	scmData.Commits[shas[1]] = &scm.Commit{
+		Sha: shas[1],
+		Author: scm.Signature{
+			Name:  "Jane Doe",
+			Email: "jane@example.com",
+			Login: "jdoe",
+		},
+	}
+
+	generator := &changelog.Generator{
+		Dir:            dir,
+		From:           shas[0],
+		To:             shas[1],
+		ScmClient:      scmClient,
+		Owner:          "acme",
+		RepositoryName: "changelog",
+	}
+	result, err := generator.Generate()
+	require.NoError(t, err)
+
+	spec := result.Release.Spec
+	require.Len(t, spec.PullRequests, 1)
+	require.NotNil(t, spec.PullRequests[0].User)
+	assert.Equal(t, "jdoe", spec.PullRequests[0].User.Login)
+	assert.Equal(t, "jane@example.com", spec.PullRequests[0].User.Email)
+
+	require.Len(t, spec.Commits, 1)
+	require.NotNil(t, spec.Commits[0].Author)
+	assert.Equal(t, "jdoe", spec.Commits[0].Author.Login, "the commit author has the login of the git provider")
+	assert.Equal(t, spec.PullRequests[0].User, spec.Commits[0].Author, "the pull request and commit author are merged into one person")
+}
+
+func TestPullRequestNumbers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  string
+		github   bool
+		pulls    string
+		expected []string
+	}{
+		{
+			name:     "squash merged commit",
+			message:  "feat: add the issues view (#2)",
+			expected: []string{"2"},
+		},
+		{
+			name:     "merge commit subject",
+			message:  "Merge pull request #3 from acme/issues-view\n\nAdd the issues view",
+			expected: []string{"3"},
+		},
+		{
+			name:     "pull requests of the commit on GitHub",
+			message:  "feat: add the issues view",
+			github:   true,
+			pulls:    `[{"number": 4}]`,
+			expected: []string{"4"},
+		},
+		{
+			name:     "message and pull requests of the commit on GitHub",
+			message:  "feat: add the issues view (#2)",
+			github:   true,
+			pulls:    `[{"number": 2}, {"number": 4}]`,
+			expected: []string{"2", "4"},
+		},
+		{
+			name:    "pull requests of the commit are only looked up on GitHub",
+			message: "feat: add the issues view",
+			pulls:   `[{"number": 4}]`,
+		},
+		{
+			name:    "no pull request",
+			message: "feat: add the issues view",
+			github:  true,
+			pulls:   `[]`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := cli.NewCLIClient("", nil)
+			dir, shas := gittest.CreateRepository(t, g, gittest.Messages("chore: initial commit", tc.message)...)
+
+			scmClient, scmData := fake.NewDefault()
+			for _, n := range []int{2, 3, 4} {
+				scmData.PullRequests[n] = &scm.PullRequest{Number: n, Title: fmt.Sprintf("Pull request %d", n)}
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/repos/acme/changelog/commits/"+shas[1]+"/pulls" {
+					http.NotFound(w, r)
+					return
+				}
+				_, _ = w.Write([]byte(tc.pulls))
+			}))
+			defer server.Close()
+			baseURL, err := url.Parse(server.URL + "/")
+			require.NoError(t, err)
+			scmClient.BaseURL = baseURL
+			if tc.github {
+				scmClient.Driver = scm.DriverGithub
+			}
+
+			generator := &changelog.Generator{
+				Dir:            dir,
+				From:           shas[0],
+				To:             shas[1],
+				ScmClient:      scmClient,
+				Owner:          "acme",
+				RepositoryName: "changelog",
+			}
+			result, err := generator.Generate()
+			require.NoError(t, err)
+
+			spec := result.Release.Spec
+			var ids []string
+			for _, pr := range spec.PullRequests {
+				ids = append(ids, pr.ID)
+			}
+			assert.Equal(t, tc.expected, ids)
+			require.Len(t, spec.Commits, 1)
+			assert.Equal(t, tc.expected, spec.Commits[0].IssueIDs, "the commit is linked to its pull requests")
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+)
+
+const (
+	// ReleaseNoteNone the release note text which leaves the change out of the changelog
+	ReleaseNoteNone = "NONE"
+)
+
+var (
+	// ReleaseNoteBlockRegex matches a Kubernetes style ```release-note fenced block
+	ReleaseNoteBlockRegex = regexp.MustCompile("(?s)```release-notes?[ \t]*\n(.*?)```")
+	// ReleaseNoteTrailerRegex matches a `Release-Note:` trailer line
+	ReleaseNoteTrailerRegex = regexp.MustCompile(`(?i)^release[- ]notes?:[ \t]*(.+)$`)
+	// TrailerRegex matches a trailer line such as `Signed-off-by: ...`
+	TrailerRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*( [A-Za-z0-9-]+)?:`)
+
+	paragraphRegex = regexp.MustCompile(`\n[ \t]*\n`)
+)
+
+// ExtractReleaseNote returns the release note from a fenced release-note block or a Release-Note trailer in the
+// trailer block at the end of the text and whether one was found. Empty blocks, such as those left over from pull
+// request templates, are ignored
+func ExtractReleaseNote(text string) (string, bool) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	for _, match := range ReleaseNoteBlockRegex.FindAllStringSubmatch(text, -1) {
+		note := strings.TrimSpace(match[1])
+		if note != "" {
+			return note, true
+		}
+	}
+	for _, line := range trailers(text) {
+		match := ReleaseNoteTrailerRegex.FindStringSubmatch(line)
+		if len(match) > 1 {
+			note := strings.TrimSpace(match[1])
+			if note != "" {
+				return note, true
+			}
+		}
+	}
+	return "", false
+}
+
+// trailers returns the lines of the trailer block of the text, which is its final paragraph if every line of it is
+// a trailer or the continuation of one, so that a release note mentioned in the prose is not picked up
+func trailers(text string) []string {
+	paragraphs := paragraphRegex.Split(strings.TrimSpace(text), -1)
+	lines := strings.Split(paragraphs[len(paragraphs)-1], "\n")
+	for i, line := range lines {
+		continuation := i > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t"))
+		if !continuation && !TrailerRegex.MatchString(line) {
+			return nil
+		}
+	}
+	return lines
+}
+
+// commitBody returns the commit message without its subject line
+func commitBody(message string) string {
+	lines := strings.SplitN(message, "\n", 2)
+	if len(lines) < 2 {
+		return ""
+	}
+	return lines[1]
+}
+
+// IsReleaseNoteNone returns true if the release note asks for the change to be left out of the changelog
+func IsReleaseNoteNone(note string) bool {
+	return strings.EqualFold(strings.TrimSpace(note), ReleaseNoteNone)
+}
+
+// commitReleaseNote returns the release note of the body of the commit message or else of the pull requests it
+// belongs to
+func commitReleaseNote(cs *v1alpha1.CommitSummary, prMap map[string]*v1alpha1.IssueSummary) (string, bool) {
+	note, found := ExtractReleaseNote(commitBody(cs.Message))
+	if found {
+		return note, true
+	}
+	for _, id := range cs.IssueIDs {
+		pr := prMap[id]
+		if pr != nil {
+			note, found = ExtractReleaseNote(pr.Body)
+			if found {
+				return note, true
+			}
+		}
+	}
+	return "", false
+}
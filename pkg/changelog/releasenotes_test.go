@@ -0,0 +1,59 @@
+package changelog_test
+
+import (
+	"testing"
+
+	"github.com/shuttlerock/changlog/pkg/changelog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractReleaseNote(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+		found    bool
+	}{
+		{
+			name:     "fenced block",
+			text:     "Adds a flag.\n\n```release-note\nAdd the --foo flag\n```\n",
+			expected: "Add the --foo flag",
+			found:    true,
+		},
+		{
+			name: "empty fenced block from a template",
+			text: "Adds a flag.\n\n```release-note\n\n```\n",
+		},
+		{
+			name:     "trailer in the trailer block",
+			text:     "Adds a flag.\n\nRelease-Note: Add the --foo flag\nSigned-off-by: Jane <jane@example.com>\n",
+			expected: "Add the --foo flag",
+			found:    true,
+		},
+		{
+			name:     "only a trailer",
+			text:     "release note: NONE",
+			expected: "NONE",
+			found:    true,
+		},
+		{
+			name: "release note mentioned in the prose",
+			text: "Fixes the parsing of\nrelease notes: they were dropped.\n\nSigned-off-by: Jane <jane@example.com>\n",
+		},
+		{
+			name: "trailer before the final paragraph",
+			text: "Release-Note: Add the --foo flag\n\nMore details about the change.\n",
+		},
+		{
+			name: "final paragraph which is not a trailer block",
+			text: "Adds a flag.\n\nSee the docs for why\nRelease-Note: Add the --foo flag\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			note, found := changelog.ExtractReleaseNote(tc.text)
+			assert.Equal(t, tc.found, found)
+			assert.Equal(t, tc.expected, note)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package changelog
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/config"
+)
+
+// Rules the compiled regular expressions of the exclusion rules and bots of a changelog configuration
+type Rules struct {
+	exclusions []*commitExclusion
+	bots       *regexp.Regexp
+}
+
+// CompileRules compiles the exclusion rules and bots of the configuration so that an invalid configuration fails
+// before any changes are generated
+func CompileRules(cfg *config.Config) (*Rules, error) {
+	var exclude []config.ExclusionRule
+	if cfg != nil {
+		exclude = cfg.Exclude
+	}
+	exclusions, err := compileExclusions(exclude)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid changelog configuration")
+	}
+	bots, err := compileBots(cfg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid changelog configuration")
+	}
+	return &Rules{
+		exclusions: exclusions,
+		bots:       bots,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package changelog
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+)
+
+// SortRelease sorts the issues, pull requests and their labels and assignees so that the same changes always give
+// the same Release. The commits are left in the order of the git history
+func SortRelease(spec *v1alpha1.ReleaseSpec) {
+	for _, summaries := range [][]v1alpha1.IssueSummary{spec.Issues, spec.PullRequests} {
+		sort.SliceStable(summaries, func(i, j int) bool {
+			return issueIDLess(summaries[i].ID, summaries[j].ID)
+		})
+		for k := range summaries {
+			issue := &summaries[k]
+			sort.SliceStable(issue.Labels, func(i, j int) bool {
+				return issue.Labels[i].Name < issue.Labels[j].Name
+			})
+			sort.SliceStable(issue.Assignees, func(i, j int) bool {
+				return issue.Assignees[i].Login < issue.Assignees[j].Login
+			})
+		}
+	}
+}
+
+// issueIDLess orders numeric ids numerically before any other ids such as Jira keys which are ordered by project
+// then number
+func issueIDLess(a, b string) bool {
+	prefixA, na := splitIssueID(a)
+	prefixB, nb := splitIssueID(b)
+	if prefixA != prefixB {
+		return prefixA < prefixB
+	}
+	if na != nb {
+		return na < nb
+	}
+	return a < b
+}
+
+// splitIssueID splits an id such as 'ABC-123' or '123' into its prefix and number
+func splitIssueID(id string) (string, int) {
+	i := strings.LastIndexAny(id, "-#") + 1
+	n, err := strconv.Atoi(id[i:])
+	if err != nil {
+		return id, 0
+	}
+	return id[:i], n
+}
@@ -0,0 +1,258 @@
+package changelog
+
+import (
+	"bufio"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const (
+	// TeamsAnnotation the annotation of the JSON object of the teams of each commit indexed by the SHA of the commit
+	TeamsAnnotation = AnnotationPrefix + "teams"
+)
+
+// DefaultCodeOwnersFiles the locations of the CODEOWNERS file searched if none is configured
+var DefaultCodeOwnersFiles = []string{"CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeOwnersRule a line of a CODEOWNERS file
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// teamResolver finds the teams of commits from the team members or else the code owners of the changed files
+type teamResolver struct {
+	members    map[string][]string
+	codeOwners []codeOwnersRule
+}
+
+// loadTeams loads the team members from the configuration and the CODEOWNERS file at the To revision
+func (g *Generator) loadTeams() (*teamResolver, error) {
+	r := &teamResolver{
+		members: map[string][]string{},
+	}
+	for _, team := range g.Config.Teams {
+		for _, member := range team.Members {
+			key := strings.ToLower(strings.TrimPrefix(member, "@"))
+			r.members[key] = appendTeam(r.members[key], team.Name)
+		}
+	}
+
+	files := DefaultCodeOwnersFiles
+	if g.Config.CodeOwners != "" {
+		files = []string{g.Config.CodeOwners}
+	}
+	to, err := ResolveCommit(g.Repository, g.To)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range files {
+		file, err := to.File(name)
+		if err == object.ErrFileNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find %s at %s", name, g.To)
+		}
+		text, err := file.Contents()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load %s at %s", name, g.To)
+		}
+		r.codeOwners, err = parseCodeOwners(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", name)
+		}
+		log.Logger().Debugf("loaded %d code owners rules from %s", len(r.codeOwners), name)
+		break
+	}
+	return r, nil
+}
+
+// addCommitTeams records the teams of the commit in the result
+func (g *Generator) addCommitTeams(summary *v1alpha1.CommitSummary, commit *object.Commit) {
+	if g.teams == nil || (len(g.teams.members) == 0 && len(g.teams.codeOwners) == 0) {
+		return
+	}
+	teams := g.teams.memberTeams(summary.Author, &commit.Author)
+	if len(teams) == 0 && len(g.teams.codeOwners) > 0 {
+		files, err := commitChangedFiles(commit)
+		if err != nil {
+			log.Logger().Warnf("failed to find the files changed by commit %s: %v", commit.Hash.String(), err)
+		}
+		teams = g.teams.ownerTeams(files)
+	}
+	if len(teams) == 0 {
+		return
+	}
+	if g.result.CommitTeams == nil {
+		g.result.CommitTeams = map[string][]string{}
+	}
+	g.result.CommitTeams[summary.SHA] = teams
+}
+
+// addTeamsAnnotation annotates the release with the teams of its commits
+func (g *Generator) addTeamsAnnotation() error {
+	if len(g.result.CommitTeams) == 0 {
+		return nil
+	}
+	return setJSONAnnotation(g.result.Release, TeamsAnnotation, g.result.CommitTeams)
+}
+
+// memberTeams returns the teams the author is a member of by login or email
+func (r *teamResolver) memberTeams(author *v1alpha1.UserDetails, signature *object.Signature) []string {
+	var keys []string
+	if author != nil {
+		keys = append(keys, author.Login, author.Email)
+	}
+	keys = append(keys, signature.Email)
+	var answer []string
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		for _, team := range r.members[strings.ToLower(key)] {
+			answer = appendTeam(answer, team)
+		}
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// ownerTeams returns the teams owning the files. Owners which are teams such as @org/team are used directly and
+// users or emails are mapped to their teams
+func (r *teamResolver) ownerTeams(files []string) []string {
+	var answer []string
+	for _, file := range files {
+		var owners []string
+		for _, rule := range r.codeOwners {
+			if rule.pattern.MatchString(file) {
+				// the last matching rule takes precedence
+				owners = rule.owners
+			}
+		}
+		for _, owner := range owners {
+			name := strings.TrimPrefix(owner, "@")
+			if idx := strings.Index(name, "/"); idx >= 0 {
+				answer = appendTeam(answer, name[idx+1:])
+				continue
+			}
+			for _, team := range r.members[strings.ToLower(name)] {
+				answer = appendTeam(answer, team)
+			}
+		}
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// parseCodeOwners parses the rules of a CODEOWNERS file
+func parseCodeOwners(text string) ([]codeOwnersRule, error) {
+	var answer []codeOwnersRule
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		fields := strings.Fields(stripCodeOwnersComment(scanner.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+		pattern, err := codeOwnersPattern(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, codeOwnersRule{
+			pattern: pattern,
+			owners:  fields[1:],
+		})
+	}
+	return answer, scanner.Err()
+}
+
+// stripCodeOwnersComment removes the comment from a CODEOWNERS line. A comment starts with a # at the start of the
+// line or after whitespace while an escaped \# is part of the pattern
+func stripCodeOwnersComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case line[i] == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// codeOwnersPattern converts a gitignore style CODEOWNERS pattern into a regular expression matching the files it
+// owns. A pattern matches a file or, if its last segment has no wildcard, everything inside a directory. It is
+// anchored to the root if it has a slash other than at the end. A backslash escapes the next character
+func codeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/") && !strings.HasSuffix(pattern, "\\/")
+	p := pattern
+	if dirOnly {
+		p = strings.TrimSuffix(p, "/")
+	}
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("(^|/)")
+	}
+	wildcard := false
+	for i := 0; i < len(p); i++ {
+		switch {
+		case p[i] == '\\' && i+1 < len(p):
+			i++
+			expr.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		case strings.HasPrefix(p[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			wildcard = false
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			expr.WriteString(".*")
+			wildcard = true
+			i++
+		case p[i] == '*':
+			expr.WriteString("[^/]*")
+			wildcard = true
+		case p[i] == '?':
+			expr.WriteString("[^/]")
+			wildcard = true
+		case p[i] == '/':
+			expr.WriteString("/")
+			wildcard = false
+		default:
+			expr.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	switch {
+	case dirOnly:
+		expr.WriteString("/")
+	case wildcard:
+		// a wildcard in the last segment only matches the files directly in the directory
+		expr.WriteString("$")
+	default:
+		expr.WriteString("(/|$)")
+	}
+	answer, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid CODEOWNERS pattern %s", pattern)
+	}
+	return answer, nil
+}
+
+// appendTeam appends the team if it is not already in the list
+func appendTeam(teams []string, team string) []string {
+	for _, t := range teams {
+		if t == team {
+			return teams
+		}
+	}
+	return append(teams, team)
+}
@@ -0,0 +1,153 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// codeOwnersFile a CODEOWNERS file using the patterns described in the GitHub documentation
+const codeOwnersFile = `# the default owners of everything in the repository
+*       @acme/platform
+
+# files ending in .js anywhere
+*.js    @acme/frontend # inline comments are allowed after whitespace
+
+# files directly in docs but not in its subdirectories
+docs/*  @acme/docs
+
+# the apps directory anywhere in the repository
+apps/   @acme/apps
+
+# any logs directory and anything inside it
+**/logs @acme/logs
+
+# the build/logs directory at the root and anything inside it, taking precedence as the later match
+/build/logs/ @acme/build
+
+# the scripts directory at the root with nothing more specific
+/scripts @acme/scripts
+
+# a file whose name starts with a hash
+\#notes.txt @acme/notes
+
+# members of a team listed by email
+/src/ jane@example.com
+`
+
+func TestCodeOwnersTeams(t *testing.T) {
+	rules, err := parseCodeOwners(codeOwnersFile)
+	require.NoError(t, err)
+	r := &teamResolver{
+		members: map[string][]string{
+			"jane@example.com": {"backend"},
+		},
+		codeOwners: rules,
+	}
+
+	testCases := []struct {
+		file     string
+		expected []string
+	}{
+		{file: "README.md", expected: []string{"platform"}},
+		{file: "main.js", expected: []string{"frontend"}},
+		{file: "web/src/app.js", expected: []string{"frontend"}},
+		{file: "docs/getting-started.md", expected: []string{"docs"}},
+		{file: "docs/build-app/troubleshooting.md", expected: []string{"platform"}},
+		{file: "apps/web/main.go", expected: []string{"apps"}},
+		{file: "services/apps/main.go", expected: []string{"apps"}},
+		{file: "build/logs/out.log", expected: []string{"build"}},
+		{file: "logs/out.log", expected: []string{"logs"}},
+		{file: "deploy/logs/out.log", expected: []string{"logs"}},
+		{file: "scripts/release.sh", expected: []string{"scripts"}},
+		{file: "tools/scripts/release.sh", expected: []string{"platform"}},
+		{file: "#notes.txt", expected: []string{"notes"}},
+		{file: "src/main.go", expected: []string{"backend"}},
+		{file: "src/main.js", expected: []string{"backend"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.file, func(t *testing.T) {
+			assert.Equal(t, tc.expected, r.ownerTeams([]string{tc.file}))
+		})
+	}
+}
+
+func TestCodeOwnersPattern(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		matches  []string
+		excludes []string
+	}{
+		{
+			pattern:  "docs/*",
+			matches:  []string{"docs/a.md"},
+			excludes: []string{"docs/a/b.md", "other/docs/a.md"},
+		},
+		{
+			pattern:  "docs",
+			matches:  []string{"docs", "docs/a/b.md", "other/docs/a.md"},
+			excludes: []string{"documents/a.md"},
+		},
+		{
+			pattern:  "/docs/",
+			matches:  []string{"docs/a.md", "docs/a/b.md"},
+			excludes: []string{"other/docs/a.md", "docs"},
+		},
+		{
+			pattern:  "docs/**",
+			matches:  []string{"docs/a.md", "docs/a/b.md"},
+			excludes: []string{"other/docs/a.md"},
+		},
+		{
+			pattern:  "src/**/test",
+			matches:  []string{"src/test/a.go", "src/a/b/test/c.go"},
+			excludes: []string{"src/testing/a.go"},
+		},
+		{
+			pattern:  "*.go",
+			matches:  []string{"main.go", "pkg/cmd/main.go"},
+			excludes: []string{"main.go.txt", "go/main.txt"},
+		},
+		{
+			pattern:  "v?.txt",
+			matches:  []string{"v1.txt"},
+			excludes: []string{"v10.txt"},
+		},
+		{
+			pattern:  `\#notes`,
+			matches:  []string{"#notes"},
+			excludes: []string{"notes"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			pattern, err := codeOwnersPattern(tc.pattern)
+			require.NoError(t, err)
+			for _, file := range tc.matches {
+				assert.True(t, pattern.MatchString(file), "pattern %s should match %s", tc.pattern, file)
+			}
+			for _, file := range tc.excludes {
+				assert.False(t, pattern.MatchString(file), "pattern %s should not match %s", tc.pattern, file)
+			}
+		})
+	}
+}
+
+func TestStripCodeOwnersComment(t *testing.T) {
+	testCases := []struct {
+		line     string
+		expected string
+	}{
+		{line: "# a comment", expected: ""},
+		{line: "*.js @owner # a comment", expected: "*.js @owner "},
+		{line: `\#file @owner`, expected: `\#file @owner`},
+		{line: "file#1 @owner", expected: "file#1 @owner"},
+		{line: "@owner", expected: "@owner"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.line, func(t *testing.T) {
+			assert.Equal(t, tc.expected, stripCodeOwnersComment(tc.line))
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	// DefaultNamespace the namespace the Release is applied to if none is specified
+	DefaultNamespace = "default"
+
+	// FieldManager the field manager of the Release when using server-side apply
+	FieldManager = "changelog"
+
+	// LabelAppName the label of the name of the application the Release belongs to
+	LabelAppName = "app.kubernetes.io/name"
+
+	// LabelAppVersion the label of the version of the application the Release belongs to
+	LabelAppVersion = "app.kubernetes.io/version"
+)
+
+var (
+	invalidNameChars       = regexp.MustCompile(`[^a-z0-9.-]+`)
+	invalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+// KubeClient returns the client to apply the Release with, lazily creating it from the kubeconfig
+func (o *Options) KubeClient() (client.Client, error) {
+	if o.KubeClientInstance == nil {
+		var err error
+		o.KubeClientInstance, err = NewKubeClient()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return o.KubeClientInstance, nil
+}
+
+// NewKubeClient creates a client for the Release resources from the kubeconfig
+func NewKubeClient() (client.Client, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the kubernetes configuration")
+	}
+	scheme := runtime.NewScheme()
+	err = v1alpha1.AddToScheme(scheme)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to register the Release resource")
+	}
+	kubeClient, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the kubernetes client")
+	}
+	return kubeClient, nil
+}
+
+// applyRelease creates or updates the Release resource of the chart in the namespace with the resolved name.
+// Server-side apply is used unless disabled, in which case the existing Release is replaced
+func (o *Options) applyRelease(release *v1alpha1.Release, chart *ChartInfo) error {
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	obj := o.releaseResource(release, chart)
+
+	ctx := context.Background()
+	if o.ServerSideApply {
+		err = kubeClient.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership)
+		if err != nil {
+			return errors.Wrapf(err, "failed to apply Release %s in namespace %s", obj.Name, obj.Namespace)
+		}
+		log.Logger().Infof("applied Release %s in namespace %s", info(obj.Name), info(obj.Namespace))
+		return nil
+	}
+
+	existing := &v1alpha1.Release{}
+	err = kubeClient.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get Release %s in namespace %s", obj.Name, obj.Namespace)
+		}
+		err = kubeClient.Create(ctx, obj)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create Release %s in namespace %s", obj.Name, obj.Namespace)
+		}
+		log.Logger().Infof("created Release %s in namespace %s", info(obj.Name), info(obj.Namespace))
+		return nil
+	}
+	obj.ResourceVersion = existing.ResourceVersion
+	err = kubeClient.Update(ctx, obj)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update Release %s in namespace %s", obj.Name, obj.Namespace)
+	}
+	log.Logger().Infof("updated Release %s in namespace %s", info(obj.Name), info(obj.Namespace))
+	return nil
+}
+
+// releaseResource returns the Release to apply with a valid resource name and the app and version labels
+func (o *Options) releaseResource(release *v1alpha1.Release, chart *ChartInfo) *v1alpha1.Release {
+	obj := release.DeepCopy()
+	obj.Name = kubeName(o.resolveReleaseName(chart))
+	obj.Namespace = o.Namespace
+	if obj.Namespace == "" {
+		obj.Namespace = DefaultNamespace
+	}
+	obj.CreationTimestamp.Reset()
+	obj.DeletionTimestamp = nil
+	obj.ResourceVersion = ""
+	obj.ManagedFields = nil
+
+	appName := chart.Name
+	if appName == "" {
+		appName = o.ScmFactory.Repository
+	}
+	version := strings.TrimPrefix(o.Version, "v")
+	if version == "" {
+		version = chart.Version
+	}
+	if obj.Labels == nil {
+		obj.Labels = map[string]string{}
+	}
+	if appName != "" {
+		obj.Labels[LabelAppName] = labelValue(appName)
+	}
+	if version != "" {
+		obj.Labels[LabelAppVersion] = labelValue(version)
+	}
+	return obj
+}
+
+// kubeName converts the text into a valid kubernetes resource name
+func kubeName(text string) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(text), "-")
+	if len(name) > 253 {
+		name = name[:253]
+	}
+	return strings.Trim(name, "-.")
+}
+
+// labelValue converts the text into a valid kubernetes label value
+func labelValue(text string) string {
+	value := invalidLabelValueChars.ReplaceAllString(text, "_")
+	if len(value) > 63 {
+		value = value[:63]
+	}
+	return strings.Trim(value, "-_.")
+}
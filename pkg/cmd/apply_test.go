@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newFakeKubeClient creates a fake client for the Release resources containing the given objects
+func newFakeKubeClient(t *testing.T, objects ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+// applyRecordingClient records the server-side apply patches which the fake client does not support
+type applyRecordingClient struct {
+	client.Client
+	patched   *v1alpha1.Release
+	patchType types.PatchType
+	options   client.PatchOptions
+}
+
+func (c *applyRecordingClient) Patch(_ context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.patched = obj.(*v1alpha1.Release).DeepCopy()
+	c.patchType = patch.Type()
+	c.options.ApplyOptions(opts)
+	return nil
+}
+
+func TestApplyReleaseServerSide(t *testing.T) {
+	kubeClient := &applyRecordingClient{Client: newFakeKubeClient(t)}
+	o := &Options{
+		Namespace:          "jx",
+		Version:            "v1.2.0",
+		ServerSideApply:    true,
+		KubeClientInstance: kubeClient,
+	}
+	release := &v1alpha1.Release{}
+	release.ResourceVersion = "42"
+	release.Spec.Commits = []v1alpha1.CommitSummary{{SHA: "abc123", Message: "fix: first"}}
+	require.NoError(t, o.applyRelease(release, &ChartInfo{Name: "app"}))
+
+	require.NotNil(t, kubeClient.patched)
+	assert.Equal(t, client.Apply.Type(), kubeClient.patchType)
+	assert.Equal(t, FieldManager, kubeClient.options.FieldManager)
+	require.NotNil(t, kubeClient.options.Force)
+	assert.True(t, *kubeClient.options.Force, "the fields of other managers are taken over")
+	assert.Equal(t, "app-1.2.0", kubeClient.patched.Name)
+	assert.Equal(t, "jx", kubeClient.patched.Namespace)
+	assert.Empty(t, kubeClient.patched.ResourceVersion, "an apply patch must not have a resource version")
+	assert.Equal(t, release.Spec, kubeClient.patched.Spec)
+}
+
+func TestApplyReleaseReplacesTheExistingRelease(t *testing.T) {
+	kubeClient := newFakeKubeClient(t)
+	// the fake client does not support server-side apply patches
+	o := &Options{
+		Namespace:          "jx",
+		Version:            "v1.2.0+build.1",
+		ServerSideApply:    false,
+		KubeClientInstance: kubeClient,
+	}
+	chart := &ChartInfo{Name: "My_App", Version: "0.0.1"}
+	key := client.ObjectKey{Namespace: "jx", Name: "my-app-1.2.0-build.1"}
+
+	release := &v1alpha1.Release{}
+	release.Name = "ignored"
+	release.Spec.Commits = []v1alpha1.CommitSummary{{SHA: "abc123", Message: "fix: first"}}
+	require.NoError(t, o.applyRelease(release, chart))
+
+	created := &v1alpha1.Release{}
+	require.NoError(t, kubeClient.Get(context.Background(), key, created))
+	assert.Equal(t, "My_App", created.Labels[LabelAppName])
+	assert.Equal(t, "1.2.0_build.1", created.Labels[LabelAppVersion])
+	require.Len(t, created.Spec.Commits, 1)
+	assert.Equal(t, "ignored", release.Name, "the generated Release is not modified")
+
+	release.Spec.Commits = append(release.Spec.Commits, v1alpha1.CommitSummary{SHA: "def456", Message: "fix: second"})
+	require.NoError(t, o.applyRelease(release, chart))
+
+	updated := &v1alpha1.Release{}
+	require.NoError(t, kubeClient.Get(context.Background(), key, updated))
+	require.Len(t, updated.Spec.Commits, 2)
+	assert.Equal(t, "def456", updated.Spec.Commits[1].SHA)
+
+	list := &v1alpha1.ReleaseList{}
+	require.NoError(t, kubeClient.List(context.Background(), list, client.InNamespace("jx")))
+	assert.Len(t, list.Items, 1, "the Release is updated rather than created again")
+}
+
+func TestReleaseResource(t *testing.T) {
+	o := &Options{}
+	o.ScmFactory.Repository = "changelog"
+	release := &v1alpha1.Release{}
+	release.ResourceVersion = "42"
+
+	obj := o.releaseResource(release, &ChartInfo{})
+	assert.Equal(t, DefaultNamespace, obj.Namespace)
+	assert.Equal(t, "changelog", obj.Name, "the repository name is used without a chart or version")
+	assert.Equal(t, "changelog", obj.Labels[LabelAppName])
+	assert.Empty(t, obj.Labels[LabelAppVersion])
+	assert.Empty(t, obj.ResourceVersion)
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+const (
+	// NoIssueKey the group of the missing backports which do not reference an issue
+	NoIssueKey = "no issue"
+)
+
+var (
+	// SecurityCommitTypes the conventional commit types and scopes of security fixes
+	SecurityCommitTypes = []string{"security", "sec"}
+
+	// CVERegex matches CVE identifiers in commit messages
+	CVERegex = regexp.MustCompile(`\bCVE-\d{4}-\d{4,}\b`)
+)
+
+// BackportsOptions the options for finding the fixes on the main branch which are missing from a release branch
+type BackportsOptions struct {
+	Dir        string
+	Branch     string
+	MainBranch string
+}
+
+// MissingBackport a fix on the main branch without a cherry-pick on the release branch
+type MissingBackport struct {
+	SHA        string
+	CommitInfo *changelog.CommitInfo
+	IssueKeys  []string
+}
+
+func (o *BackportsOptions) Validate() error {
+	if o.Branch == "" {
+		return options.MissingOption("branch")
+	}
+	if o.MainBranch == "" {
+		o.MainBranch = "main"
+	}
+	if o.Dir == "" {
+		o.Dir = "."
+	}
+	return nil
+}
+
+func (o *BackportsOptions) Run() error {
+	err := o.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "failed to validate")
+	}
+
+	missing, err := o.FindMissingBackports()
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		log.Logger().Infof("all fixes on %s have been backported to %s", info(o.MainBranch), info(o.Branch))
+		return nil
+	}
+
+	groups := map[string][]MissingBackport{}
+	for _, m := range missing {
+		keys := m.IssueKeys
+		if len(keys) == 0 {
+			keys = []string{NoIssueKey}
+		}
+		for _, key := range keys {
+			groups[key] = append(groups[key], m)
+		}
+	}
+	var keys []string
+	for key := range groups {
+		if key != NoIssueKey {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if len(groups[NoIssueKey]) > 0 {
+		keys = append(keys, NoIssueKey)
+	}
+
+	log.Logger().Infof("found %d fixes on %s missing from %s:", len(missing), info(o.MainBranch), info(o.Branch))
+	for _, key := range keys {
+		log.Logger().Infof("\n%s", info(key))
+		for _, m := range groups[key] {
+			ci := m.CommitInfo
+			prefix := ci.Kind
+			if ci.Feature != "" {
+				prefix += "(" + ci.Feature + ")"
+			}
+			log.Logger().Infof("  %s %s: %s", m.SHA[:7], prefix, strings.SplitN(ci.Message, "\n", 2)[0])
+		}
+	}
+	return nil
+}
+
+// FindMissingBackports returns the fix and security commits on the main branch which do not have a cherry-pick
+// on the release branch, either by the cherry-pick trailer or by having the same patch id
+func (o *BackportsOptions) FindMissingBackports() ([]MissingBackport, error) {
+	gitDir, gitConfDir, err := gitclient.FindGitConfigDir(o.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if gitDir == "" || gitConfDir == "" {
+		return nil, errors.Errorf("no git directory could be found from dir %s", o.Dir)
+	}
+
+	repo, err := git.PlainOpen(gitDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open git repository %s", gitDir)
+	}
+	mainCommits, err := changelog.FetchCommits(repo, o.Branch, o.MainBranch)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the commits between %s and %s", o.Branch, o.MainBranch)
+	}
+	branchCommits, err := changelog.FetchCommits(repo, o.MainBranch, o.Branch)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the commits between %s and %s", o.MainBranch, o.Branch)
+	}
+
+	var pickedFrom []string
+	patchIDs := map[string]bool{}
+	for k := range branchCommits {
+		c := &branchCommits[k]
+		pickedFrom = append(pickedFrom, changelog.CherryPickedFrom(c.Message)...)
+		patchID, err := changelog.PatchID(c)
+		if err != nil {
+			log.Logger().Debugf("failed to find the patch id of commit %s: %v", c.Hash.String(), err)
+		} else if patchID != "" {
+			patchIDs[patchID] = true
+		}
+	}
+
+	var answer []MissingBackport
+	for k := range mainCommits {
+		c := &mainCommits[k]
+		if len(c.ParentHashes) > 1 {
+			continue
+		}
+		ci := changelog.ParseCommit(c.Message)
+		if !isBackportCandidate(ci, c.Message) {
+			continue
+		}
+		sha := c.Hash.String()
+		if isCherryPicked(sha, pickedFrom) {
+			continue
+		}
+		patchID, err := changelog.PatchID(c)
+		if err != nil {
+			log.Logger().Debugf("failed to find the patch id of commit %s: %v", sha, err)
+		} else if patchID != "" && patchIDs[patchID] {
+			continue
+		}
+		answer = append(answer, MissingBackport{
+			SHA:        sha,
+			CommitInfo: ci,
+			IssueKeys:  uniqueStrings(changelog.JIRAIssueRegex.FindAllString(CVERegex.ReplaceAllString(c.Message, ""), -1)),
+		})
+	}
+	return answer, nil
+}
+
+// isBackportCandidate returns true for fix and security commits
+func isBackportCandidate(ci *changelog.CommitInfo, message string) bool {
+	kind := strings.ToLower(ci.Kind)
+	if kind == "fix" || changelog.ContainsAnyLabel(SecurityCommitTypes, []string{kind, ci.Feature}) {
+		return true
+	}
+	return CVERegex.MatchString(message)
+}
+
+func isCherryPicked(sha string, pickedFrom []string) bool {
+	for _, from := range pickedFrom {
+		if changelog.ShaMatches(from, sha) {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueStrings(values []string) []string {
+	var answer []string
+	for _, v := range values {
+		if stringhelpers.StringArrayIndex(answer, v) < 0 {
+			answer = append(answer, v)
+		}
+	}
+	return answer
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/cli"
+	"github.com/shuttlerock/changlog/pkg/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindMissingBackports(t *testing.T) {
+	g := cli.NewCLIClient("", nil)
+	file := func(name string) map[string]string {
+		return map[string]string{name: name + "\n"}
+	}
+	dir, shas := gittest.CreateRepository(t, g,
+		gittest.Commit{Message: "chore: initial commit", Files: file("main.txt")},
+		gittest.Commit{Message: "fix: ABC-1 ABC-1 handle the missing chart", Files: file("chart.txt")},
+		gittest.Commit{Message: "feat: ABC-2 add the issues view", Files: file("view.txt")},
+		gittest.Commit{Message: "fix: escape the markdown for CVE-2021-12345", Files: file("escape.txt")},
+		gittest.Commit{Message: "security: upgrade the base image", Files: file("image.txt")},
+		gittest.Commit{Message: "fix: ABC-3 cherry-picked with the trailer", Files: file("trailer.txt")},
+		gittest.Commit{Message: "fix(sec): ABC-4 same change", Files: file("patch.txt")},
+	)
+	gittest.Run(t, g, dir, "branch", "-M", "main")
+
+	// the release branch has a cherry-pick with the trailer and a copy of the same change with another message
+	gittest.Run(t, g, dir, "checkout", "-q", "-b", "release-1.0", shas[0])
+	gittest.Run(t, g, dir, "cherry-pick", "-x", shas[5])
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "patch.txt"), []byte("patch.txt\n"), 0o600))
+	gittest.Run(t, g, dir, "add", "-A")
+	gittest.Run(t, g, dir, "commit", "-q", "-m", "fix: backport the change")
+
+	o := &BackportsOptions{Dir: dir, Branch: "release-1.0"}
+	require.NoError(t, o.Validate())
+	missing, err := o.FindMissingBackports()
+	require.NoError(t, err)
+
+	var missingSHAs []string
+	issueKeys := map[string][]string{}
+	for _, m := range missing {
+		missingSHAs = append(missingSHAs, m.SHA)
+		issueKeys[m.SHA] = m.IssueKeys
+	}
+	assert.Equal(t, []string{shas[4], shas[3], shas[1]}, missingSHAs, "the fixes and security changes without a backport, most recent first")
+	assert.Equal(t, []string{"ABC-1"}, issueKeys[shas[1]])
+	assert.Empty(t, issueKeys[shas[3]], "a CVE is not an issue key")
+	assert.Equal(t, "security", missing[0].CommitInfo.Kind)
+}
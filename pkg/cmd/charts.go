@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x-plugins/jx-changelog/pkg/helmhelpers"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// ChartInfo a chart to generate a Release for
+type ChartInfo struct {
+	// Name the name of the chart or empty if the repository has no chart
+	Name string
+
+	// Version the version in the Chart.yaml file
+	Version string
+
+	// File the Chart.yaml file of the chart
+	File string
+
+	// TemplatesDir the directory the Release YAML is written to or empty if it is not written
+	TemplatesDir string
+
+	// Paths the source paths relative to the root of the repository of the commits included in the release.
+	// If empty all commits are included
+	Paths []string
+}
+
+// findCharts returns the charts to generate a Release for. If the repository has more than one chart in the charts
+// directory then there is one per chart, each only including the commits which change its source paths
+func (o *Options) findCharts(dir, gitDir string) ([]*ChartInfo, error) {
+	chartFiles, err := filepath.Glob(filepath.Join(dir, "charts", "*", helmhelpers.ChartFileName))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find the charts")
+	}
+	var charts []*ChartInfo
+	if len(chartFiles) > 1 {
+		for _, chartFile := range chartFiles {
+			chart, err := loadChartInfo(chartFile)
+			if err != nil {
+				return nil, err
+			}
+			chart.Paths, err = o.chartPaths(chart, gitDir)
+			if err != nil {
+				return nil, err
+			}
+			charts = append(charts, chart)
+		}
+	} else {
+		chartFile, err := helmhelpers.FindChart(dir)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not find helm chart")
+		}
+		chart := &ChartInfo{}
+		if chartFile == "" {
+			log.Logger().Infof("no chart directory found in %s", dir)
+		} else {
+			chart, err = loadChartInfo(chartFile)
+			if err != nil {
+				return nil, err
+			}
+		}
+		charts = append(charts, chart)
+	}
+
+	if o.Chart != "" {
+		var selected []*ChartInfo
+		for _, chart := range charts {
+			if chart.Name == o.Chart {
+				selected = append(selected, chart)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, errors.Errorf("no chart called %s found in %s", o.Chart, dir)
+		}
+		charts = selected
+	}
+	if o.TemplatesDir != "" {
+		if len(charts) > 1 {
+			return nil, errors.Errorf("the templates directory cannot be specified for more than one chart, use --chart to select one")
+		}
+		charts[0].TemplatesDir = o.TemplatesDir
+	}
+	return charts, nil
+}
+
+// loadChartInfo loads the name of the chart defaulting to the name of its directory
+func loadChartInfo(chartFile string) (*ChartInfo, error) {
+	chartDir := filepath.Dir(chartFile)
+	chart := &ChartInfo{
+		Name:         filepath.Base(chartDir),
+		File:         chartFile,
+		TemplatesDir: filepath.Join(chartDir, "templates"),
+	}
+	data, err := ioutil.ReadFile(chartFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", chartFile)
+	}
+	metadata := &ChartDependency{}
+	err = yaml.Unmarshal(data, metadata)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal YAML file %s", chartFile)
+	}
+	if metadata.Name != "" {
+		chart.Name = metadata.Name
+	}
+	chart.Version = metadata.Version
+	return chart, nil
+}
+
+// chartPaths returns the chart directory and the configured source paths of the chart relative to the git directory
+func (o *Options) chartPaths(chart *ChartInfo, gitDir string) ([]string, error) {
+	absGitDir, err := filepath.Abs(gitDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the absolute path of %s", gitDir)
+	}
+	absChartDir, err := filepath.Abs(filepath.Dir(chart.File))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the absolute path of %s", chart.File)
+	}
+	chartDir, err := filepath.Rel(absGitDir, absChartDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the path of %s in %s", chart.File, gitDir)
+	}
+	paths := []string{filepath.ToSlash(chartDir)}
+	if o.Config != nil {
+		for _, sources := range o.Config.Charts {
+			if sources.Name == chart.Name {
+				paths = append(paths, sources.Paths...)
+			}
+		}
+	}
+	return paths, nil
+}
@@ -3,92 +3,85 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
-	chgit "github.com/antham/chyle/chyle/git"
-	"github.com/ghodss/yaml"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/termcolor"
 	"github.com/pkg/errors"
-	"github.com/shuttlerock/changlog/pkg/users"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+	"github.com/shuttlerock/changlog/pkg/config"
+	"github.com/shuttlerock/changlog/pkg/issuefile"
+	"github.com/shuttlerock/changlog/pkg/retry"
+	"github.com/shuttlerock/changlog/pkg/tracker"
 	"github.com/shuttlerock/devops-api/api/v1alpha1"
-	"gopkg.in/src-d/go-git.v4/plumbing/object"
 	"io/ioutil"
-	"os"
 	"path/filepath"
-	"regexp"
-	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
 	"github.com/jenkins-x-plugins/jx-changelog/pkg/gits"
-	"github.com/jenkins-x-plugins/jx-changelog/pkg/helmhelpers"
 	"github.com/jenkins-x-plugins/jx-changelog/pkg/issues"
-	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/cli"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
-	"github.com/jenkins-x/jx-helpers/v3/pkg/kube"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/scmhelpers"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/stringhelpers"
 
 	"github.com/jenkins-x/jx-logging/v3/pkg/log"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const (
-	ReleaseName = `{{ .Chart.Name }}-{{ .Chart.Version | replace "+" "_" }}`
-	SpecName    = `{{ .Chart.Name }}`
-)
-
-var (
-	info         = termcolor.ColorInfo
-	groupCounter = 0
-	// ConventionalCommitTitles textual descriptions for
-	// Conventional Commit types: https://conventionalcommits.org/
-	ConventionalCommitTitles = map[string]*CommitGroup{
-		"feat":     createCommitGroup("New Features"),
-		"fix":      createCommitGroup("Bug Fixes"),
-		"perf":     createCommitGroup("Performance Improvements"),
-		"refactor": createCommitGroup("Code Refactoring"),
-		"docs":     createCommitGroup("Documentation"),
-		"test":     createCommitGroup("Tests"),
-		"revert":   createCommitGroup("Reverts"),
-		"style":    createCommitGroup("Styles"),
-		"chore":    createCommitGroup("Chores"),
-		"":         createCommitGroup(""),
-	}
-	JIRAIssueRegex = regexp.MustCompile(`\b[A-Z][A-Z0-9_]+-\d+\b`)
-)
-
-func createCommitGroup(title string) *CommitGroup {
-	groupCounter++
-	return &CommitGroup{
-		Title: title,
-		Order: groupCounter,
-	}
-}
+var info = termcolor.ColorInfo
 
 type Options struct {
 	options.BaseOptions
-	GitDir             string
-	OutputMarkdownFile string
-	ReleaseYamlFile    string
-	ScmFactory         scmhelpers.Options
-	State              State
-	Version            string
-	TemplatesDir       string
-	jiraProject        string
-	jiraAPIToken       string
-	jiraUsername       string
-	jiraServerURL      string
+	AggregatePrereleases bool
+	Apply                bool
+	CallTimeout          time.Duration
+	Check                bool
+	Config               *config.Config
+	Chart                string
+	ConfigFile           string
+	DependenciesDir      string
+	DiagnosticsFile      string
+	FirstParent          bool
+	GitDir               string
+	GroupBy              string
+	IssuesFile           string
+	IssueTracker         string
+	KubeClientInstance   client.Client
+	Namespace            string
+	NestMergedCommits    bool
+	NoHelm               bool
+	Offline              bool
+	OutputMarkdownFile   string
+	ReleaseYamlFile      string
+	Retries              int
+	ScmFactory           scmhelpers.Options
+	ServerSideApply      bool
+	ShowExcluded         bool
+	State                State
+	Strict               bool
+	Timeout              time.Duration
+	Version              string
+	TemplatesDir         string
+	Umbrella             bool
+	View                 string
+	jiraProject          string
+	jiraAPIToken         string
+	jiraUsername         string
+	jiraServerURL        string
 }
 
 type State struct {
+	Context         context.Context
 	Tracker         issues.IssueProvider
-	FoundIssueNames map[string]bool
+	StaleFiles      []StaleFile
+	Diagnostics     []changelog.Diagnostic
 	LoggedIssueKind bool
 	Release         *v1alpha1.Release
+	Rules           *changelog.Rules
 }
 
 func (o *Options) Validate() error {
@@ -97,11 +90,65 @@ func (o *Options) Validate() error {
 		return errors.Wrapf(err, "failed to validate base options")
 	}
 
-	err = o.ScmFactory.Validate()
-	if err != nil {
-		return errors.Wrapf(err, "failed to discover git repository")
+	if o.Offline {
+		o.discoverOffline()
+	} else {
+		err = o.ScmFactory.Validate()
+		if err != nil {
+			// the git provider is only needed to enrich the release so local output can still be generated
+			if o.Apply {
+				return errors.Wrapf(err, "failed to discover git repository")
+			}
+			log.Logger().Warnf("failed to discover the git provider so pull requests and users will not be looked up: %v", err)
+			o.ScmFactory.ScmClient = nil
+			o.discoverOffline()
+		}
+	}
+
+	if o.IssueTracker == "" {
+		o.IssueTracker = IssueTrackerJira
+	}
+	if stringhelpers.StringArrayIndex(IssueTrackerValues, o.IssueTracker) < 0 {
+		return options.InvalidOption("issue-tracker", o.IssueTracker, IssueTrackerValues)
+	}
+	if o.IssueTracker == IssueTrackerFile && o.IssuesFile == "" {
+		return options.MissingOption("issues-file")
+	}
+	if o.Strict && !o.hasIssueTracker() {
+		return options.InvalidOptionf("strict", o.Strict, "there is no issue tracker to look up the issues in so use --issue-tracker %s", IssueTrackerFile)
+	}
+
+	if o.GroupBy == "" {
+		o.GroupBy = changelog.GroupByType
+	}
+	if stringhelpers.StringArrayIndex(changelog.GroupByValues, o.GroupBy) < 0 {
+		return options.InvalidOption("group-by", o.GroupBy, changelog.GroupByValues)
+	}
+
+	if o.View == "" {
+		o.View = changelog.ViewCommits
+	}
+	if stringhelpers.StringArrayIndex(changelog.ViewValues, o.View) < 0 {
+		return options.InvalidOption("view", o.View, changelog.ViewValues)
+	}
+	if o.View == changelog.ViewIssues && o.GroupBy != changelog.GroupByType {
+		return options.InvalidOptionf("group-by", o.GroupBy, "the issues view is grouped by epic so it cannot be grouped by %s", o.GroupBy)
 	}
 
+	if o.Config == nil {
+		if o.ConfigFile == "" {
+			o.Config, err = config.LoadConfigFromDir(o.ScmFactory.Dir)
+		} else {
+			o.Config, err = config.LoadConfig(o.ConfigFile)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to load changelog configuration")
+		}
+	}
+	o.State.Rules, err = changelog.CompileRules(o.Config)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -111,9 +158,24 @@ func (o *Options) Run() error {
 		return errors.Wrapf(err, "failed to validate")
 	}
 
+	if o.State.Context == nil {
+		ctx := context.Background()
+		if o.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+			defer cancel()
+		}
+		o.State.Context = ctx
+	}
+
 	dir := o.ScmFactory.Dir
 
-	previousRev, _, err := gits.GetCommitPointedToByPreviousTag(o.Git(), dir)
+	currentRev, currentTag, err := gits.GetCommitPointedToByLatestTag(o.Git(), dir)
+	if err != nil {
+		return err
+	}
+
+	previousRev, _, err := o.previousRevision(dir, currentTag)
 	if err != nil {
 		return err
 	}
@@ -129,38 +191,6 @@ func (o *Options) Run() error {
 		}
 	}
 
-	currentRev, _, err := gits.GetCommitPointedToByLatestTag(o.Git(), dir)
-	if err != nil {
-		return err
-	}
-
-	templatesDir := o.TemplatesDir
-	dir = o.ScmFactory.Dir
-	if templatesDir == "" {
-		chartFile, err := helmhelpers.FindChart(dir)
-		if err != nil {
-			return errors.Wrap(err, "could not find helm chart")
-		}
-		if chartFile == "" {
-			log.Logger().Infof("no chart directory found in %s", dir)
-			templatesDir = ""
-		} else {
-			path, _ := filepath.Split(chartFile)
-			if path == "" {
-				log.Logger().Infof("no chart directory found in %s", dir)
-				templatesDir = ""
-			} else {
-				templatesDir = filepath.Join(path, "templates")
-			}
-		}
-	}
-	if templatesDir != "" {
-		err = os.MkdirAll(templatesDir, files.DefaultDirWritePermissions)
-		if err != nil {
-			return errors.Wrapf(err, "failed to create the templates directory %s", templatesDir)
-		}
-	}
-
 	log.Logger().Infof("Generating change log from git ref %s => %s", info(previousRev), info(currentRev))
 
 	gitDir, gitConfDir, err := gitclient.FindGitConfigDir(dir)
@@ -186,243 +216,208 @@ func (o *Options) Run() error {
 	}
 	o.State.Tracker = tracker
 
-	o.State.FoundIssueNames = map[string]bool{}
+	charts, err := o.findCharts(dir, gitDir)
+	if err != nil {
+		return err
+	}
 
-	commits, _ := chgit.FetchCommits(gitDir, previousRev, currentRev)
+	created, err := releaseTimestamp(gitDir, currentRev)
+	if err != nil {
+		return err
+	}
 
-	if commits != nil {
-		commitSlice := *commits
-		if len(commitSlice) > 0 {
-			if strings.HasPrefix(commitSlice[0].Message, "release ") {
-				// remove the release commit from the log
-				tmp := commitSlice[1:]
-				commits = &tmp
-			}
+	results := make([]*changelog.Result, 0, len(charts))
+	for _, chart := range charts {
+		result, err := o.generateRelease(chart, gitDir, previousRev, currentRev, created)
+		if err != nil {
+			return o.failWithDiagnostics(errors.Wrapf(err, "failed to create the release of chart %s", chart.Name))
 		}
-		log.Logger().Debugf("Found commits:")
-		if commits != nil {
-			for k := range *commits {
-				commit := (*commits)[k]
-				log.Logger().Debugf("  commit %s", commit.Hash)
-				log.Logger().Debugf("  Author: %s <%s>", commit.Author.Name, commit.Author.Email)
-				log.Logger().Debugf("  Date: %s", commit.Committer.When.Format(time.ANSIC))
-				log.Logger().Debugf("      %s\n\n\n", commit.Message)
-			}
+		results = append(results, result)
+	}
+	if o.Strict {
+		err = checkStrict(o.State.Diagnostics)
+		if err != nil {
+			return o.failWithDiagnostics(err)
 		}
 	}
-	version := o.Version
 
-	release := &v1alpha1.Release{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Release",
-			APIVersion: v1alpha1.GroupVersion.Group + "/" + v1alpha1.GroupVersion.Version,
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name: ReleaseName,
-			CreationTimestamp: metav1.Time{
-				Time: time.Now(),
-			},
-			DeletionTimestamp: &metav1.Time{},
-		},
-		Spec: v1alpha1.ReleaseSpec{
-			//Name:          SpecName,
-			//Version:       version,
-			//GitOwner:      gitInfo.Organisation,
-			//GitRepository: gitInfo.Name,
-			//GitHTTPURL:    gitInfo.HttpsURL(),
-			//GitCloneURL:   gitInfo.CloneURL,
-			//Commits:       []v1alpha1.CommitSummary{},
-			Issues: []v1alpha1.IssueSummary{},
-			//PullRequests:  []v1alpha1.IssueSummary{},
-		},
+	var markdowns []string
+	for i, chart := range charts {
+		markdown, err := o.writeRelease(chart, results[i], gitInfo)
+		if err != nil {
+			return o.failWithDiagnostics(errors.Wrapf(err, "failed to create the release of chart %s", chart.Name))
+		}
+		if len(charts) > 1 && markdown != "" {
+			markdown = "# " + chart.Name + "\n\n" + markdown
+		}
+		markdowns = append(markdowns, markdown)
 	}
 
-	scmClient := o.ScmFactory.ScmClient
-	resolver := users.GitUserResolver{
-		GitProvider: scmClient,
+	err = o.writeDiagnostics()
+	if err != nil {
+		return err
 	}
-	if commits != nil {
-		for k := range *commits {
-			c := (*commits)[k]
-			if len(c.ParentHashes) <= 1 {
-				o.addCommit(&release.Spec, &c, &resolver)
-			}
+
+	if o.OutputMarkdownFile != "" {
+		markdown := strings.Join(markdowns, "\n")
+		err = o.writeOutput(o.OutputMarkdownFile, []byte(markdown))
+		if err != nil {
+			return errors.Wrapf(err, "failed to save changelog markdown file %s", o.OutputMarkdownFile)
+		}
+	}
+
+	if o.Check {
+		return o.checkStaleFiles()
+	}
+	return nil
+}
+
+// generateRelease generates the Release of the chart from the commits between the two revisions which change the
+// source paths of the chart along with the changes of its dependencies when creating an umbrella release
+func (o *Options) generateRelease(chart *ChartInfo, gitDir, previousRev, currentRev string, created time.Time) (*changelog.Result, error) {
+	generator := o.newGenerator(gitDir, previousRev, currentRev)
+	generator.Paths = chart.Paths
+	generator.Created = created
+	result, err := generator.GenerateContext(o.context())
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Umbrella && chart.File != "" {
+		if o.DependenciesDir == "" {
+			o.DependenciesDir = filepath.Dir(gitDir)
+		}
+		err = o.addDependencyUpdates(result, chart.File, gitDir, previousRev, currentRev)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to add the changes of the chart dependencies")
+		}
+		changelog.SortRelease(&result.Release.Spec)
+	}
+	o.State.Diagnostics = append(o.State.Diagnostics, result.Diagnostics...)
+	return result, nil
+}
+
+// writeRelease writes and applies the generated Release of the chart and returns its changelog markdown
+func (o *Options) writeRelease(chart *ChartInfo, result *changelog.Result, gitInfo *giturl.GitRepository) (string, error) {
+	templatesDir := chart.TemplatesDir
+	version := o.Version
+	release := result.Release
+	o.State.Release = release
+
+	if o.ShowExcluded {
+		logExcludedCommits(result.ExcludedCommits)
+	}
+
+	var err error
+	markdown := ""
+	if o.OutputMarkdownFile != "" {
+		markdown, err = o.GenerateMarkdown(result, gitInfo)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to generate the changelog markdown")
 		}
 	}
 
 	// now lets marshal the release YAML
-	data, err := yaml.Marshal(release)
+	data, err := o.marshalRelease(release, chart)
 
 	if err != nil {
-		return errors.Wrap(err, "failed to unmarshal Release")
+		return "", errors.Wrap(err, "failed to unmarshal Release")
 	}
 	if data == nil {
-		return fmt.Errorf("could not marshal release to yaml")
+		return "", fmt.Errorf("could not marshal release to yaml")
 	}
 
 	if templatesDir != "" {
 		releaseFile := filepath.Join(templatesDir, o.ReleaseYamlFile)
-		err = ioutil.WriteFile(releaseFile, data, files.DefaultFileWritePermissions)
+		err = o.writeOutput(releaseFile, data)
 		if err != nil {
-			return errors.Wrapf(err, "failed to save Release YAML file %s", releaseFile)
+			return "", errors.Wrapf(err, "failed to save Release YAML file %s", releaseFile)
 		}
-		log.Logger().Infof("generated: %s", info(releaseFile))
 		cleanVersion := strings.TrimPrefix(version, "v")
 		release.Spec.Version = cleanVersion
 	}
 
-	return nil
+	if o.Apply && !o.Check {
+		err = o.applyRelease(release, chart)
+		if err != nil {
+			return "", err
+		}
+	}
+	return markdown, nil
 }
 
-// CreateIssueProvider creates the issue provider
-func (o *Options) CreateIssueProvider() (issues.IssueProvider, error) {
-	return issues.CreateJiraIssueProvider(o.jiraServerURL, o.jiraUsername, o.jiraAPIToken, o.jiraProject, true)
+// newGenerator creates the generator of the changes between the two revisions of the git repository
+func (o *Options) newGenerator(gitDir, previousRev, currentRev string) *changelog.Generator {
+	return &changelog.Generator{
+		Dir:               gitDir,
+		From:              previousRev,
+		To:                currentRev,
+		Tracker:           o.State.Tracker,
+		ScmClient:         o.ScmFactory.ScmClient,
+		Owner:             o.ScmFactory.Owner,
+		RepositoryName:    o.ScmFactory.Repository,
+		Config:            o.Config,
+		Rules:             o.State.Rules,
+		FirstParent:       o.FirstParent,
+		NestMergedCommits: o.NestMergedCommits,
+		Offline:           o.Offline,
+		Retry: retry.Options{
+			Timeout:    o.CallTimeout,
+			MaxRetries: o.Retries,
+		},
+	}
 }
 
-func (o *Options) Git() gitclient.Interface {
-	return cli.NewCLIClient("", nil)
+// context returns the context of the run which is done when the overall timeout expires
+func (o *Options) context() context.Context {
+	if o.State.Context == nil {
+		return context.Background()
+	}
+	return o.State.Context
 }
 
-func (o *Options) addCommit(spec *v1alpha1.ReleaseSpec, commit *object.Commit, resolver *users.GitUserResolver) {
-	// TODO
-	url := ""
-	branch := "master"
+// hasIssueTracker returns true if the issues referenced by the commits are looked up in an issue tracker
+func (o *Options) hasIssueTracker() bool {
+	if o.IssueTracker == IssueTrackerFile {
+		return true
+	}
+	return !o.Offline && o.jiraServerURL != ""
+}
 
-	var author, committer *v1alpha1.UserDetails
-	var err error
-	sha := commit.Hash.String()
-	if commit.Author.Email != "" && commit.Author.Name != "" {
-		author, err = resolver.GitSignatureAsUser(&commit.Author)
+// CreateIssueProvider creates the issue provider. There is no provider for a remote issue tracker when offline or
+// if it is not configured
+func (o *Options) CreateIssueProvider() (issues.IssueProvider, error) {
+	if o.IssueTracker == IssueTrackerFile {
+		provider, err := issuefile.NewProvider(o.IssuesFile)
 		if err != nil {
-			log.Logger().Warnf("failed to enrich commit with issues, error getting git signature for git author %s: %v", commit.Author, err)
+			return nil, err
 		}
+		return provider, nil
 	}
-	if commit.Committer.Email != "" && commit.Committer.Name != "" {
-		committer, err = resolver.GitSignatureAsUser(&commit.Committer)
-		if err != nil {
-			log.Logger().Warnf("failed to enrich commit with issues, error getting git signature for git committer %s: %v", commit.Committer, err)
-		}
+	if o.Offline {
+		log.Logger().Infof("offline so not looking up issues in %s", o.IssueTracker)
+		return nil, nil
 	}
-	commitSummary := v1alpha1.CommitSummary{
-		Message:   commit.Message,
-		URL:       url,
-		SHA:       sha,
-		Author:    author,
-		Branch:    branch,
-		Committer: committer,
+	if o.jiraServerURL == "" {
+		log.Logger().Infof("no %s server configured so not looking up issues", o.IssueTracker)
+		return nil, nil
 	}
-
-	o.addIssuesAndPullRequests(spec, &commitSummary, commit)
-	spec.Commits = append(spec.Commits, commitSummary)
-}
-
-func (o *Options) addIssuesAndPullRequests(spec *v1alpha1.ReleaseSpec, commit *v1alpha1.CommitSummary, rawCommit *object.Commit) {
-	tracker := o.State.Tracker
-
-	regex := JIRAIssueRegex
-	message := fullCommitMessageText(rawCommit)
-
-	matches := regex.FindAllStringSubmatch(message, -1)
-
-	resolver := users.GitUserResolver{
-		GitProvider: o.ScmFactory.ScmClient,
-	}
-	for _, match := range matches {
-		for _, result := range match {
-			result = strings.TrimPrefix(result, "#")
-			if _, ok := o.State.FoundIssueNames[result]; !ok {
-				o.State.FoundIssueNames[result] = true
-				issue, err := tracker.GetIssue(result)
-				if err != nil {
-					log.Logger().Warnf("Failed to lookup issue %s in issue tracker %s due to %s", result, tracker.HomeURL(), err)
-					continue
-				}
-				if issue == nil {
-					log.Logger().Warnf("Failed to find issue %s for repository %s", result, tracker.HomeURL())
-					continue
-				}
-
-				user, err := resolver.Resolve(&issue.Author)
-				if err != nil {
-					log.Logger().Warnf("Failed to resolve user %v for issue %s repository %s", issue.Author, result, tracker.HomeURL())
-				}
-
-				var closedBy *v1alpha1.UserDetails
-				if issue.ClosedBy == nil {
-					log.Logger().Warnf("Failed to find closedBy user for issue %s repository %s", result, tracker.HomeURL())
-				} else {
-					u, err := resolver.Resolve(issue.ClosedBy)
-					if err != nil {
-						log.Logger().Warnf("Failed to resolve closedBy user %v for issue %s repository %s", issue.Author, result, tracker.HomeURL())
-					} else if u != nil {
-						closedBy = u
-					}
-				}
-
-				var assignees []v1alpha1.UserDetails
-				if issue.Assignees == nil {
-					log.Logger().Warnf("Failed to find assignees for issue %s repository %s", result, tracker.HomeURL())
-				} else {
-					u, err := resolver.GitUserSliceAsUserDetailsSlice(issue.Assignees)
-					if err != nil {
-						log.Logger().Warnf("Failed to resolve Assignees %v for issue %s repository %s", issue.Assignees, result, tracker.HomeURL())
-					}
-					assignees = u
-				}
-
-				labels := toV1Labels(issue.Labels)
-				commit.IssueIDs = append(commit.IssueIDs, result)
-				issueSummary := v1alpha1.IssueSummary{
-					ID:                result,
-					URL:               issue.Link,
-					Title:             issue.Title,
-					Body:              issue.Body,
-					User:              user,
-					CreationTimestamp: kube.ToMetaTime(&issue.Created),
-					ClosedBy:          closedBy,
-					Assignees:         assignees,
-					Labels:            labels,
-				}
-				state := issue.State
-				if state != "" {
-					issueSummary.State = state
-				}
-				if issue.PullRequest {
-					spec.PullRequests = append(spec.PullRequests, issueSummary)
-				} else {
-					spec.Issues = append(spec.Issues, issueSummary)
-				}
-			}
-		}
+	provider, err := issues.CreateJiraIssueProvider(o.jiraServerURL, o.jiraUsername, o.jiraAPIToken, o.jiraProject, true)
+	if err != nil {
+		return nil, err
 	}
-}
-
-// toV1Labels converts git labels to IssueLabel
-func toV1Labels(labels []string) []v1alpha1.IssueLabel {
-	var answer []v1alpha1.IssueLabel
-	for _, label := range labels {
-		answer = append(answer, v1alpha1.IssueLabel{
-			Name: label,
-		})
+	if jira, ok := provider.(*issues.JiraService); ok {
+		fields := config.IssueFields{}
+		if o.Config != nil {
+			fields = o.Config.IssueFields
+		}
+		return tracker.NewJiraProvider(jira, fields), nil
 	}
-	return answer
+	return provider, nil
 }
 
-// fullCommitMessageText returns the commit message
-func fullCommitMessageText(commit *object.Commit) string {
-	answer := commit.Message
-	fn := func(parent *object.Commit) {
-		text := parent.Message
-		if text != "" {
-			sep := "\n"
-			if strings.HasSuffix(answer, "\n") {
-				sep = ""
-			}
-			answer += sep + text
-		}
-	}
-	fn(commit)
-	return answer
+func (o *Options) Git() gitclient.Interface {
+	return cli.NewCLIClient("", nil)
 }
 
 func (o *Options) getTemplateResult(releaseSpec *v1alpha1.ReleaseSpec, templateName, templateText, templateFile string) (string, error) {
@@ -458,121 +453,3 @@ func isReleaseNotFound(err error, gitKind string) bool {
 		return scmhelpers.IsScmNotFound(err)
 	}
 }
-
-type CommitInfo struct {
-	Kind    string
-	Feature string
-	Message string
-	group   *CommitGroup
-}
-
-type GroupAndCommitInfos struct {
-	group   *CommitGroup
-	commits []string
-}
-
-type CommitGroup struct {
-	Title string
-	Order int
-}
-
-// ParseCommit parses a conventional commit
-// see: https://conventionalcommits.org/
-func ParseCommit(message string) *CommitInfo {
-	answer := &CommitInfo{
-		Message: message,
-	}
-
-	idx := strings.Index(message, ":")
-	if idx > 0 {
-		kind := message[0:idx]
-		if strings.HasSuffix(kind, ")") {
-			ix := strings.Index(kind, "(")
-			if ix > 0 {
-				answer.Feature = strings.TrimSpace(kind[ix+1 : len(kind)-1])
-				kind = strings.TrimSpace(kind[0:ix])
-			}
-		}
-		answer.Kind = kind
-		rest := strings.TrimSpace(message[idx+1:])
-
-		answer.Message = rest
-	}
-	return answer
-}
-
-func describeIssueShort(issue *v1alpha1.IssueSummary) string {
-	prefix := ""
-	id := issue.ID
-	if len(id) > 0 {
-		// lets only add the hash prefix for numeric ids
-		_, err := strconv.Atoi(id)
-		if err == nil {
-			prefix = "#"
-		}
-	}
-	return "[" + prefix + issue.ID + "](" + issue.URL + ") "
-}
-
-func describeUser(info *giturl.GitRepository, user *v1alpha1.UserDetails) string {
-	answer := ""
-	if user != nil {
-		userText := ""
-		login := user.Login
-		url := user.URL
-		label := login
-		if label == "" {
-			label = user.Name
-		}
-		if url == "" && login != "" {
-			url = stringhelpers.UrlJoin(info.HostURL(), login)
-		}
-		if url == "" {
-			userText = label
-		} else if label != "" {
-			userText = "[" + label + "](" + url + ")"
-		}
-		if userText != "" {
-			answer = " (" + userText + ")"
-		}
-	}
-	return answer
-}
-
-func describeCommit(info *giturl.GitRepository, cs *v1alpha1.CommitSummary, ci *CommitInfo, issueMap map[string]*v1alpha1.IssueSummary) string {
-	prefix := ""
-	if ci.Feature != "" {
-		prefix = ci.Feature + ": "
-	}
-	message := strings.TrimSpace(ci.Message)
-	lines := strings.Split(message, "\n")
-
-	// TODO add link to issue etc...
-	user := cs.Author
-	if user == nil {
-		user = cs.Committer
-	}
-	issueText := ""
-	for k := range cs.IssueIDs {
-		issue := issueMap[cs.IssueIDs[k]]
-		if issue != nil {
-			issueText += " " + describeIssueShort(issue)
-		}
-	}
-	return prefix + lines[0] + describeUser(info, user) + issueText
-}
-
-func (c *CommitInfo) Group() *CommitGroup {
-	if c.group == nil {
-		c.group = ConventionalCommitTitles[strings.ToLower(c.Kind)]
-	}
-	return c.group
-}
-
-func (c *CommitInfo) Title() string {
-	return c.Group().Title
-}
-
-func (c *CommitInfo) Order() int {
-	return c.Group().Order
-}
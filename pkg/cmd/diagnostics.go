@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+)
+
+// checkStrict fails if any of the issue keys referenced by the commits of the charts and their dependencies could
+// not be found
+func checkStrict(diagnostics []changelog.Diagnostic) error {
+	failed := changelog.StrictDiagnostics(diagnostics)
+	if len(failed) == 0 {
+		return nil
+	}
+	// the charts of a repository share commits so the same issue can be missing more than once
+	reported := map[string]bool{}
+	for _, d := range failed {
+		key := d.Commit + "/" + d.Issue
+		if reported[key] {
+			continue
+		}
+		reported[key] = true
+		log.Logger().Infof("commit %s references issue %s: %s", d.Commit, info(d.Issue), d.Cause)
+	}
+	return errors.Errorf("%d issues referenced by the commits could not be found", len(reported))
+}
+
+// failWithDiagnostics writes the diagnostics report before returning the error so that the report explains why the
+// run failed
+func (o *Options) failWithDiagnostics(err error) error {
+	reportErr := o.writeDiagnostics()
+	if reportErr != nil {
+		log.Logger().Warnf("%v", reportErr)
+	}
+	return err
+}
+
+// writeDiagnostics writes the JSON diagnostics report if a report file is specified unless only checking that the
+// generated files are up to date
+func (o *Options) writeDiagnostics() error {
+	if o.DiagnosticsFile == "" {
+		return nil
+	}
+	if o.Check {
+		log.Logger().Debugf("not writing the diagnostics report %s when checking the generated files", o.DiagnosticsFile)
+		return nil
+	}
+	report := changelog.DiagnosticsReport{
+		Diagnostics: o.State.Diagnostics,
+	}
+	if report.Diagnostics == nil {
+		report.Diagnostics = []changelog.Diagnostic{}
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the diagnostics report")
+	}
+	err = os.MkdirAll(filepath.Dir(o.DiagnosticsFile), files.DefaultDirWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the directory of %s", o.DiagnosticsFile)
+	}
+	err = ioutil.WriteFile(o.DiagnosticsFile, append(data, '\n'), files.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save diagnostics report %s", o.DiagnosticsFile)
+	}
+	log.Logger().Infof("wrote %d diagnostics to %s", len(report.Diagnostics), info(o.DiagnosticsFile))
+	return nil
+}
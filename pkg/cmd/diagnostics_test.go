@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/cli"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+	"github.com/shuttlerock/changlog/pkg/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateStrictRequiresIssueTracker(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options Options
+		valid   bool
+	}{
+		{
+			name:    "jira without a server",
+			options: Options{Offline: true, Strict: true},
+		},
+		{
+			name:    "jira when offline",
+			options: Options{Offline: true, Strict: true, jiraServerURL: "https://jira.example.com"},
+		},
+		{
+			name:    "issues file",
+			options: Options{Offline: true, Strict: true, IssueTracker: IssueTrackerFile, IssuesFile: "issues.yaml"},
+			valid:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := tc.options
+			o.ScmFactory.Dir = t.TempDir()
+			err := o.Validate()
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "strict")
+		})
+	}
+}
+
+func TestRunStrictFailsOnMissingIssues(t *testing.T) {
+	g := cli.NewCLIClient("", nil)
+	dir, shas := gittest.CreateRepository(t, g,
+		gittest.Commit{
+			Message: "chore: initial commit",
+			Files:   map[string]string{"charts/app/Chart.yaml": "apiVersion: v2\nname: app\nversion: 0.0.1\n"},
+			Tag:     "v1.0.0",
+		},
+		gittest.Commit{Message: "feat: ABC-1 add the issues view"},
+		gittest.Commit{Message: "fix: ABC-2 render the missing issues", Tag: "v1.1.0"},
+	)
+	issuesFile := filepath.Join(dir, "issues.yaml")
+	require.NoError(t, ioutil.WriteFile(issuesFile, []byte("- key: ABC-1\n  title: Add the issues view\n"), 0o600))
+
+	outDir := t.TempDir()
+	o := &Options{
+		Offline:            true,
+		Strict:             true,
+		IssueTracker:       IssueTrackerFile,
+		IssuesFile:         issuesFile,
+		DiagnosticsFile:    filepath.Join(outDir, "diagnostics.json"),
+		OutputMarkdownFile: filepath.Join(outDir, "CHANGELOG.md"),
+	}
+	o.ScmFactory.Dir = dir
+	err := o.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 issues referenced by the commits could not be found")
+
+	assert.NoFileExists(t, o.OutputMarkdownFile, "nothing is written when strict mode fails")
+	data, err := ioutil.ReadFile(o.DiagnosticsFile)
+	require.NoError(t, err, "the diagnostics report explains the failure")
+	report := changelog.DiagnosticsReport{}
+	require.NoError(t, json.Unmarshal(data, &report))
+	failed := changelog.StrictDiagnostics(report.Diagnostics)
+	require.Len(t, failed, 1)
+	assert.Equal(t, shas[2], failed[0].Commit)
+	assert.Equal(t, "ABC-2", failed[0].Issue)
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+)
+
+// logExcludedCommits lists the commits which were dropped from the release
+func logExcludedCommits(excluded []changelog.ExcludedCommit) {
+	if len(excluded) == 0 {
+		log.Logger().Info("no commits were excluded")
+		return
+	}
+	log.Logger().Infof("excluded %d commits:", len(excluded))
+	for _, c := range excluded {
+		log.Logger().Infof("  %s %s: %s", c.SHA[:7], c.Subject, info(c.Reason))
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+)
+
+// releaseNamePlaceholder stands in for the release name template while the rest of the Release YAML is escaped
+const releaseNamePlaceholder = "changelog-release-name-placeholder"
+
+var (
+	// HelmEscaper replaces the delimiters of Helm templates with actions which output them, so that the text renders
+	// as is
+	HelmEscaper = strings.NewReplacer("{{", `{{ "{{" }}`, "}}", `{{ "}}" }}`)
+
+	// HelmUnescaper reverses the replacements of the HelmEscaper
+	HelmUnescaper = strings.NewReplacer(`{{ "{{" }}`, "{{", `{{ "}}" }}`, "}}")
+)
+
+// EscapeHelm escapes the text so that it is not treated as a template when Helm renders it
+func EscapeHelm(text string) string {
+	return HelmEscaper.Replace(text)
+}
+
+// UnescapeHelm returns the text escaped by EscapeHelm as it was before it was escaped
+func UnescapeHelm(text string) string {
+	return HelmUnescaper.Replace(text)
+}
+
+// marshalRelease marshals the Release YAML of the chart. Without Helm the name is resolved from the chart name and
+// version and the text is not escaped
+func (o *Options) marshalRelease(release *v1alpha1.Release, chart *ChartInfo) ([]byte, error) {
+	if o.NoHelm {
+		out := release.DeepCopy()
+		out.Name = o.resolveReleaseName(chart)
+		return yaml.Marshal(out)
+	}
+	return MarshalHelmRelease(release)
+}
+
+// MarshalHelmRelease marshals the Release YAML for the templates directory of a chart. The text is escaped so that
+// commit messages and issues are not rendered by Helm, while the name is the ReleaseName template
+func MarshalHelmRelease(release *v1alpha1.Release) ([]byte, error) {
+	out := release.DeepCopy()
+	out.Name = releaseNamePlaceholder
+	data, err := yaml.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	name, err := yaml.Marshal(changelog.ReleaseName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal the release name")
+	}
+	text := strings.Replace(EscapeHelm(string(data)), releaseNamePlaceholder, strings.TrimSpace(string(name)), 1)
+	return []byte(text), nil
+}
+
+// resolveReleaseName returns the name the ReleaseName template renders to for the chart
+func (o *Options) resolveReleaseName(chart *ChartInfo) string {
+	name := ""
+	version := strings.TrimPrefix(o.Version, "v")
+	if chart != nil {
+		name = chart.Name
+		if version == "" {
+			version = chart.Version
+		}
+	}
+	if name == "" {
+		name = o.ScmFactory.Repository
+	}
+	if version == "" {
+		return name
+	}
+	return name + "-" + strings.ReplaceAll(version, "+", "_")
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// helmTextMessages commit messages which Helm would fail to render or render differently if they were not escaped
+var helmTextMessages = []string{
+	"fix: render {{ .Values.image }} as is",
+	"fix: close the }} and open the {{ delimiters",
+	`docs: explain {{ "{{" }} escaping`,
+	"chore: nothing to escape",
+}
+
+func newHelmTextRelease() *v1alpha1.Release {
+	release := &v1alpha1.Release{}
+	release.Name = "app-1.0.0"
+	for _, message := range helmTextMessages {
+		release.Spec.Commits = append(release.Spec.Commits, v1alpha1.CommitSummary{Message: message})
+	}
+	return release
+}
+
+func TestUnescapeHelm(t *testing.T) {
+	for _, text := range helmTextMessages {
+		assert.Equal(t, text, UnescapeHelm(EscapeHelm(text)))
+	}
+}
+
+func TestMarshalHelmReleaseRendersAsIs(t *testing.T) {
+	release := newHelmTextRelease()
+	data, err := MarshalHelmRelease(release)
+	require.NoError(t, err)
+
+	// render the Release YAML the way Helm does with text/template and the sprig replace function
+	funcs := template.FuncMap{
+		"replace": func(old, new, src string) string {
+			return strings.ReplaceAll(src, old, new)
+		},
+	}
+	tmpl, err := template.New("release.yaml").Funcs(funcs).Parse(string(data))
+	require.NoError(t, err)
+	values := map[string]interface{}{
+		"Chart": map[string]interface{}{
+			"Name":    "app",
+			"Version": "1.0.0+build.1",
+		},
+	}
+	var buffer bytes.Buffer
+	require.NoError(t, tmpl.Execute(&buffer, values))
+
+	rendered := &v1alpha1.Release{}
+	require.NoError(t, yaml.Unmarshal(buffer.Bytes(), rendered))
+	assert.Equal(t, "app-1.0.0_build.1", rendered.Name)
+	assert.Equal(t, release.Spec, rendered.Spec)
+}
+
+func TestMarshalHelmReleaseUnescapes(t *testing.T) {
+	release := newHelmTextRelease()
+	data, err := MarshalHelmRelease(release)
+	require.NoError(t, err)
+
+	loaded := &v1alpha1.Release{}
+	require.NoError(t, yaml.Unmarshal([]byte(UnescapeHelm(string(data))), loaded))
+	assert.Equal(t, release.Spec, loaded.Spec)
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+)
+
+// GenerateMarkdown generates the markdown document for the commits of the result
+func (o *Options) GenerateMarkdown(result *changelog.Result, gitInfo *giturl.GitRepository) (string, error) {
+	renderer := &changelog.MarkdownRenderer{
+		GroupBy: o.GroupBy,
+		View:    o.View,
+		Config:  o.Config,
+	}
+	return renderer.Render(result, gitInfo)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/gitdiscovery"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/giturl"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+)
+
+const (
+	// IssueTrackerJira looks up the issues in Jira
+	IssueTrackerJira = "jira"
+
+	// IssueTrackerFile looks up the issues in a local YAML or JSON file
+	IssueTrackerFile = "file"
+)
+
+// IssueTrackerValues the supported issue trackers
+var IssueTrackerValues = []string{IssueTrackerJira, IssueTrackerFile}
+
+// discoverOffline finds the git repository details from the local clone without creating a git provider client
+func (o *Options) discoverOffline() {
+	f := &o.ScmFactory
+	if f.SourceURL == "" {
+		var err error
+		f.SourceURL, err = gitdiscovery.FindGitURLFromDir(f.Dir, f.PreferUpstream)
+		if err != nil {
+			log.Logger().Debugf("failed to discover the git URL in dir %s: %v", f.Dir, err)
+		}
+	}
+	if f.GitURL == nil && f.SourceURL != "" {
+		gitURL, err := giturl.ParseGitURL(f.SourceURL)
+		if err != nil {
+			log.Logger().Debugf("failed to parse git URL %s: %v", f.SourceURL, err)
+		} else {
+			f.GitURL = gitURL
+		}
+	}
+	if f.GitURL != nil {
+		if f.Owner == "" {
+			f.Owner = f.GitURL.Organisation
+		}
+		if f.Repository == "" {
+			f.Repository = f.GitURL.Name
+		}
+	}
+	if f.GitURL == nil {
+		f.GitURL = &giturl.GitRepository{}
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// StaleFile a generated file whose content on disk differs from the generated content
+type StaleFile struct {
+	File string
+	Diff string
+}
+
+// writeOutput writes the generated file if its content changed. In check mode the file is not written and a
+// unified diff of the change is recorded instead
+func (o *Options) writeOutput(file string, data []byte) error {
+	existing, err := ioutil.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to load file %s", file)
+	}
+	if err == nil && bytes.Equal(existing, data) {
+		log.Logger().Infof("unchanged: %s", info(file))
+		return nil
+	}
+
+	if o.Check {
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(string(existing)),
+			B:        splitLines(string(data)),
+			FromFile: file,
+			ToFile:   file + " (generated)",
+			Context:  3,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to diff file %s", file)
+		}
+		o.State.StaleFiles = append(o.State.StaleFiles, StaleFile{File: file, Diff: diff})
+		return nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(file), files.DefaultDirWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the directory of %s", file)
+	}
+	err = ioutil.WriteFile(file, data, files.DefaultFileWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save file %s", file)
+	}
+	log.Logger().Infof("generated: %s", info(file))
+	return nil
+}
+
+// checkStaleFiles fails if any of the generated files are out of date, logging the diff of each one
+func (o *Options) checkStaleFiles() error {
+	stale := o.State.StaleFiles
+	if len(stale) == 0 {
+		return nil
+	}
+	for _, s := range stale {
+		log.Logger().Infof("%s is out of date:\n%s", info(s.File), s.Diff)
+	}
+	return errors.Errorf("%d generated files are out of date, please regenerate the changelog", len(stale))
+}
+
+// splitLines splits the text into lines keeping their line endings for diffing
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
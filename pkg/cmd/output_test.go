@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shuttlerock/changlog/pkg/changelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteOutput(t *testing.T) {
+	generated := "# Changes\n\n* fix: escape the markdown\n"
+	testCases := []struct {
+		name     string
+		existing string
+		missing  bool
+		check    bool
+		stale    bool
+		diff     []string
+	}{
+		{
+			name:     "unchanged",
+			existing: generated,
+		},
+		{
+			name:     "changed",
+			existing: "# Changes\n",
+		},
+		{
+			name:    "missing",
+			missing: true,
+		},
+		{
+			name:     "check unchanged",
+			existing: generated,
+			check:    true,
+		},
+		{
+			name:     "check changed",
+			existing: "# Changes\n\n* fix: old change\n",
+			check:    true,
+			stale:    true,
+			diff:     []string{"-* fix: old change\n", "+* fix: escape the markdown\n"},
+		},
+		{
+			name:    "check missing",
+			missing: true,
+			check:   true,
+			stale:   true,
+			diff:    []string{"+# Changes\n", "+* fix: escape the markdown\n"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "docs", "CHANGELOG.md")
+			var modified time.Time
+			if !tc.missing {
+				require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+				require.NoError(t, ioutil.WriteFile(file, []byte(tc.existing), 0o600))
+				// an old modification time shows whether the file is written again
+				modified = time.Unix(0, 0)
+				require.NoError(t, os.Chtimes(file, modified, modified))
+			}
+
+			o := &Options{Check: tc.check}
+			require.NoError(t, o.writeOutput(file, []byte(generated)))
+
+			err := o.checkStaleFiles()
+			if !tc.stale {
+				require.NoError(t, err)
+				assert.Empty(t, o.State.StaleFiles)
+			} else {
+				require.Error(t, err, "the check fails so that the command exits with an error")
+				assert.Contains(t, err.Error(), "1 generated files are out of date")
+				require.Len(t, o.State.StaleFiles, 1)
+				assert.Equal(t, file, o.State.StaleFiles[0].File)
+				assert.Contains(t, o.State.StaleFiles[0].Diff, "--- "+file+"\n+++ "+file+" (generated)\n")
+				for _, line := range tc.diff {
+					assert.Contains(t, o.State.StaleFiles[0].Diff, line)
+				}
+			}
+
+			data, err := ioutil.ReadFile(file)
+			if tc.check {
+				// the file is left as it is when checking
+				if tc.missing {
+					assert.True(t, os.IsNotExist(err))
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tc.existing, string(data))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, generated, string(data))
+			}
+			if !tc.missing && (tc.check || tc.existing == generated) {
+				stat, err := os.Stat(file)
+				require.NoError(t, err)
+				assert.Equal(t, modified, stat.ModTime(), "the file is not written again")
+			}
+		})
+	}
+}
+
+func TestWriteDiagnostics(t *testing.T) {
+	diagnostics := []changelog.Diagnostic{{Kind: changelog.DiagnosticIssueNotFound, Commit: "abc123", Issue: "ABC-1"}}
+
+	o := &Options{DiagnosticsFile: filepath.Join(t.TempDir(), "out", "diagnostics.json")}
+	o.State.Diagnostics = diagnostics
+	require.NoError(t, o.writeDiagnostics())
+	data, err := ioutil.ReadFile(o.DiagnosticsFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"issue": "ABC-1"`)
+
+	o = &Options{DiagnosticsFile: filepath.Join(t.TempDir(), "diagnostics.json"), Check: true}
+	o.State.Diagnostics = diagnostics
+	require.NoError(t, o.writeDiagnostics())
+	assert.NoFileExists(t, o.DiagnosticsFile, "nothing is written when checking the generated files")
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/options"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReleaseNameVersionRegex matches the version suffix of the name of a Release created from the ReleaseName template
+var ReleaseNameVersionRegex = regexp.MustCompile(`^(.+?)-v?\d+\.\d+.*$`)
+
+// PruneOptions the options for deleting the old Release resources of each application
+type PruneOptions struct {
+	Namespace          string
+	Keep               int
+	OlderThan          time.Duration
+	DryRun             bool
+	KubeClientInstance client.Client
+	Now                time.Time
+}
+
+// PrunedRelease a Release which is deleted, or would be deleted in a dry run
+type PrunedRelease struct {
+	App     string
+	Name    string
+	Created time.Time
+}
+
+func (o *PruneOptions) Validate() error {
+	if o.Keep <= 0 && o.OlderThan <= 0 {
+		return options.MissingOption("keep")
+	}
+	if o.Keep < 0 {
+		return options.InvalidOptionf("keep", o.Keep, "the number of releases to keep cannot be negative")
+	}
+	if o.Namespace == "" {
+		o.Namespace = DefaultNamespace
+	}
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+	if o.KubeClientInstance == nil {
+		var err error
+		o.KubeClientInstance, err = NewKubeClient()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *PruneOptions) Run() error {
+	err := o.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "failed to validate")
+	}
+
+	pruned, err := o.FindPrunedReleases()
+	if err != nil {
+		return err
+	}
+	if len(pruned) == 0 {
+		log.Logger().Infof("no Release resources to prune in namespace %s", info(o.Namespace))
+		return nil
+	}
+
+	ctx := context.Background()
+	for _, p := range pruned {
+		if o.DryRun {
+			log.Logger().Infof("would delete Release %s of %s created %s", info(p.Name), p.App, p.Created.Format(time.RFC3339))
+			continue
+		}
+		release := &v1alpha1.Release{}
+		release.Name = p.Name
+		release.Namespace = o.Namespace
+		err = o.KubeClientInstance.Delete(ctx, release)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete Release %s in namespace %s", p.Name, o.Namespace)
+		}
+		log.Logger().Infof("deleted Release %s of %s created %s", info(p.Name), p.App, p.Created.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// FindPrunedReleases returns the Release resources to delete for each application. When both are specified a
+// Release is only deleted if it is not one of the newest ones to keep and is older than the maximum age
+func (o *PruneOptions) FindPrunedReleases() ([]PrunedRelease, error) {
+	list := &v1alpha1.ReleaseList{}
+	err := o.KubeClientInstance.List(context.Background(), list, client.InNamespace(o.Namespace))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the Release resources in namespace %s", o.Namespace)
+	}
+
+	apps := map[string][]*v1alpha1.Release{}
+	for k := range list.Items {
+		r := &list.Items[k]
+		app := releaseApp(r)
+		apps[app] = append(apps[app], r)
+	}
+	var names []string
+	for app := range apps {
+		names = append(names, app)
+	}
+	sort.Strings(names)
+
+	var answer []PrunedRelease
+	for _, app := range names {
+		releases := apps[app]
+		sort.Slice(releases, func(i, j int) bool {
+			ti := releases[i].CreationTimestamp.Time
+			tj := releases[j].CreationTimestamp.Time
+			if !ti.Equal(tj) {
+				return ti.After(tj)
+			}
+			return releases[i].Name > releases[j].Name
+		})
+		for i, r := range releases {
+			if o.Keep > 0 && i < o.Keep {
+				continue
+			}
+			created := r.CreationTimestamp.Time
+			if o.OlderThan > 0 && o.Now.Sub(created) <= o.OlderThan {
+				continue
+			}
+			answer = append(answer, PrunedRelease{
+				App:     app,
+				Name:    r.Name,
+				Created: created,
+			})
+		}
+	}
+	return answer, nil
+}
+
+// releaseApp returns the application of the Release from its label or else its name without the version
+func releaseApp(release *v1alpha1.Release) string {
+	app := release.Labels[LabelAppName]
+	if app != "" {
+		return app
+	}
+	match := ReleaseNameVersionRegex.FindStringSubmatch(release.Name)
+	if len(match) > 1 {
+		return match[1]
+	}
+	return release.Name
+}
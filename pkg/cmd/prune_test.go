@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	newRelease := func(name, app string, age time.Duration) client.Object {
+		r := &v1alpha1.Release{}
+		r.Name = name
+		r.Namespace = "jx"
+		r.CreationTimestamp = metav1.NewTime(now.Add(-age))
+		if app != "" {
+			r.Labels = map[string]string{LabelAppName: app}
+		}
+		return r
+	}
+	day := 24 * time.Hour
+
+	testCases := []struct {
+		name      string
+		keep      int
+		olderThan time.Duration
+		dryRun    bool
+		expected  []string
+	}{
+		{
+			name:     "keep the newest of each application",
+			keep:     2,
+			expected: []string{"api-1.0.0", "frontend-1.0.0"},
+		},
+		{
+			name:      "older than",
+			olderThan: 15 * day,
+			expected:  []string{"api-1.0.0", "api-1.1.0", "frontend-1.0.0", "frontend-1.1.0"},
+		},
+		{
+			name:      "only older than and not one of the newest",
+			keep:      1,
+			olderThan: 25 * day,
+			expected:  []string{"api-1.0.0"},
+		},
+		{
+			name:     "dry run",
+			keep:     1,
+			dryRun:   true,
+			expected: []string{"api-1.0.0", "api-1.1.0", "frontend-1.0.0", "frontend-1.1.0"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := []client.Object{
+				newRelease("api-1.0.0", "", 30*day),
+				newRelease("api-1.1.0", "", 20*day),
+				newRelease("api-1.2.0", "", 10*day),
+				newRelease("frontend-1.0.0", "web", 20*day),
+				newRelease("frontend-1.1.0", "web", 20*day),
+				newRelease("frontend-1.2.0", "web", 1*day),
+			}
+			kubeClient := newFakeKubeClient(t, objects...)
+			o := &PruneOptions{
+				Namespace:          "jx",
+				Keep:               tc.keep,
+				OlderThan:          tc.olderThan,
+				DryRun:             tc.dryRun,
+				KubeClientInstance: kubeClient,
+				Now:                now,
+			}
+
+			pruned, err := o.FindPrunedReleases()
+			require.NoError(t, err)
+			var names []string
+			for _, p := range pruned {
+				names = append(names, p.Name)
+			}
+			assert.ElementsMatch(t, tc.expected, names)
+
+			require.NoError(t, o.Run())
+			list := &v1alpha1.ReleaseList{}
+			require.NoError(t, kubeClient.List(context.Background(), list, client.InNamespace("jx")))
+			deleted := len(objects) - len(list.Items)
+			if tc.dryRun {
+				assert.Equal(t, 0, deleted, "nothing is deleted in a dry run")
+				return
+			}
+			assert.Equal(t, len(tc.expected), deleted)
+			for _, r := range list.Items {
+				assert.NotContains(t, tc.expected, r.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// SourceDateEpochEnv the environment variable of the reproducible builds timestamp in seconds since the unix epoch
+const SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// releaseTimestamp returns the creation time of the release so that the same revision always gives the same Release.
+// SOURCE_DATE_EPOCH is used if set, otherwise the commit time of the revision
+func releaseTimestamp(gitDir, rev string) (time.Time, error) {
+	epoch := os.Getenv(SourceDateEpochEnv)
+	if epoch != "" {
+		seconds, err := strconv.ParseInt(strings.TrimSpace(epoch), 10, 64)
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "invalid $%s %s", SourceDateEpochEnv, epoch)
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	repo, err := git.PlainOpen(gitDir)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to open git repository %s", gitDir)
+	}
+	commit, err := changelog.ResolveCommit(repo, rev)
+	if err != nil {
+		log.Logger().Warnf("failed to find the time of commit %s so using the current time: %v", rev, err)
+		return time.Now().UTC(), nil
+	}
+	return commit.Committer.When.UTC(), nil
+}
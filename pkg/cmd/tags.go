@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/jenkins-x-plugins/jx-changelog/pkg/gits"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient"
+	"github.com/pkg/errors"
+)
+
+// GetCommitPointedToByPreviousSemverTag returns the SHA of the commit pointed to by the tag with the highest semantic
+// version lower than the given tag as well as the tag name. When the given tag is a final release the pre-release tags
+// are skipped so that the changelog covers all of the release candidates. Empty strings are returned if the given tag
+// is not a semantic version or there is no lower version
+func GetCommitPointedToByPreviousSemverTag(g gitclient.Interface, dir, currentTag string) (string, string, error) {
+	current, err := version.NewSemver(currentTag)
+	if err != nil {
+		return "", "", nil
+	}
+	finalRelease := current.Prerelease() == ""
+
+	tags, err := gits.FilterTags(g, dir, "")
+	if err != nil {
+		return "", "", errors.Wrapf(err, "listing tags in %s", dir)
+	}
+
+	var previous *version.Version
+	previousTag := ""
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		v, err := version.NewSemver(tag)
+		if err != nil || !v.LessThan(current) {
+			continue
+		}
+		if finalRelease && v.Prerelease() != "" {
+			continue
+		}
+		if previous == nil || v.GreaterThan(previous) {
+			previous = v
+			previousTag = tag
+		}
+	}
+	if previousTag == "" {
+		return "", "", nil
+	}
+
+	commitSHA, err := g.Command(dir, "rev-list", "-n", "1", previousTag)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "running for git rev-list -n 1 %s", previousTag)
+	}
+	return commitSHA, previousTag, nil
+}
+
+// previousRevision returns the commit of the previous release, preferring the previous semantic version when
+// aggregating pre-releases and falling back to the previous tag by date for tags which are not semantic versions.
+// Empty strings are returned for the first final release so that it covers all of its release candidates from the
+// first commit
+func (o *Options) previousRevision(dir, currentTag string) (string, string, error) {
+	if o.AggregatePrereleases && currentTag != "" {
+		previousRev, previousTag, err := GetCommitPointedToByPreviousSemverTag(o.Git(), dir, currentTag)
+		if err != nil {
+			return "", "", err
+		}
+		if previousRev != "" || isFinalSemver(currentTag) {
+			return previousRev, previousTag, nil
+		}
+	}
+	return gits.GetCommitPointedToByPreviousTag(o.Git(), dir)
+}
+
+// isFinalSemver returns true if the tag is a semantic version which is not a pre-release
+func isFinalSemver(tag string) bool {
+	v, err := version.NewSemver(tag)
+	return err == nil && v.Prerelease() == ""
+}
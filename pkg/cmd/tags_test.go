@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/cli"
+	"github.com/shuttlerock/changlog/pkg/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTaggedRepository creates a git repository with an initial commit followed by one commit per tag in the given
+// order and returns the repository directory and the SHA of each tagged commit by tag
+func createTaggedRepository(t *testing.T, g gitclient.Interface, tags ...string) (string, map[string]string) {
+	commits := []gittest.Commit{{Message: "initial commit"}}
+	for _, tag := range tags {
+		commits = append(commits, gittest.Commit{Message: "release " + tag, Tag: tag})
+	}
+	dir, shas := gittest.CreateRepository(t, g, commits...)
+	answer := map[string]string{"": shas[0]}
+	for i, tag := range tags {
+		answer[tag] = shas[i+1]
+	}
+	return dir, answer
+}
+
+func TestGetCommitPointedToByPreviousSemverTag(t *testing.T) {
+	g := cli.NewCLIClient("", nil)
+	testCases := []struct {
+		name     string
+		tags     []string
+		current  string
+		expected string
+	}{
+		{
+			name:     "previous final release",
+			tags:     []string{"v1.0.0", "v1.1.0"},
+			current:  "v1.1.0",
+			expected: "v1.0.0",
+		},
+		{
+			name:     "final release skips its release candidates",
+			tags:     []string{"v1.0.0", "v1.1.0-rc.1", "v1.1.0-rc.2", "v1.1.0"},
+			current:  "v1.1.0",
+			expected: "v1.0.0",
+		},
+		{
+			name:     "release candidate after the previous release candidate",
+			tags:     []string{"v1.0.0", "v1.1.0-rc.1", "v1.1.0-rc.2"},
+			current:  "v1.1.0-rc.2",
+			expected: "v1.1.0-rc.1",
+		},
+		{
+			name:     "highest lower version rather than the latest tag",
+			tags:     []string{"v1.0.0", "v1.1.0", "v1.0.1"},
+			current:  "v1.0.1",
+			expected: "v1.0.0",
+		},
+		{
+			name:     "first final release",
+			tags:     []string{"v1.0.0-rc.1", "v1.0.0"},
+			current:  "v1.0.0",
+			expected: "",
+		},
+		{
+			name:     "not a semantic version",
+			tags:     []string{"release-1", "release-2"},
+			current:  "release-2",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, shas := createTaggedRepository(t, g, tc.tags...)
+			sha, tag, err := GetCommitPointedToByPreviousSemverTag(g, dir, tc.current)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, tag)
+			if tc.expected == "" {
+				assert.Empty(t, sha)
+			} else {
+				assert.Equal(t, shas[tc.expected], sha)
+			}
+		})
+	}
+}
+
+func TestPreviousRevision(t *testing.T) {
+	g := cli.NewCLIClient("", nil)
+	testCases := []struct {
+		name      string
+		tags      []string
+		current   string
+		aggregate bool
+		expected  string
+	}{
+		{
+			name:      "first final release after release candidates starts from the first commit",
+			tags:      []string{"v1.0.0-rc.1", "v1.0.0-rc.2", "v1.0.0"},
+			current:   "v1.0.0",
+			aggregate: true,
+			expected:  "",
+		},
+		{
+			name:      "final release after the previous final release",
+			tags:      []string{"v1.0.0", "v1.1.0-rc.1", "v1.1.0"},
+			current:   "v1.1.0",
+			aggregate: true,
+			expected:  "v1.0.0",
+		},
+		{
+			name:      "tags which are not semantic versions use the previous tag",
+			tags:      []string{"release-1", "release-2"},
+			current:   "release-2",
+			aggregate: true,
+			expected:  "release-1",
+		},
+		{
+			name:      "not aggregating uses the previous tag",
+			tags:      []string{"v1.0.0-rc.1", "v1.0.0"},
+			current:   "v1.0.0",
+			aggregate: false,
+			expected:  "v1.0.0-rc.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, shas := createTaggedRepository(t, g, tc.tags...)
+			o := &Options{AggregatePrereleases: tc.aggregate}
+			sha, _, err := o.previousRevision(dir, tc.current)
+			require.NoError(t, err)
+			if tc.expected == "" {
+				assert.Empty(t, sha)
+			} else {
+				assert.Equal(t, shas[tc.expected], sha)
+			}
+		})
+	}
+}
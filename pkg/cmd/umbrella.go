@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x-plugins/jx-changelog/pkg/helmhelpers"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient/gitdiscovery"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+)
+
+const (
+	// DependencyAnnotationPrefix the prefix of the annotation recording the version change of a chart dependency.
+	// The annotation name is followed by the name of the dependency
+	DependencyAnnotationPrefix = changelog.AnnotationPrefix + "dependency."
+)
+
+// ChartDependency a dependency of a helm chart
+type ChartDependency struct {
+	Name       string `json:"name"`
+	Version    string `json:"version,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Alias      string `json:"alias,omitempty"`
+}
+
+type chartDependencies struct {
+	Dependencies []ChartDependency `json:"dependencies,omitempty"`
+}
+
+// loadChartDependencies loads the dependencies of the chart file at the given git revision
+func loadChartDependencies(g gitclient.Interface, gitDir, rev, chartFile string) ([]ChartDependency, error) {
+	absGitDir, err := filepath.Abs(gitDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the absolute path of %s", gitDir)
+	}
+	absChartFile, err := filepath.Abs(chartFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the absolute path of %s", chartFile)
+	}
+	path, err := filepath.Rel(absGitDir, absChartFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the path of %s in %s", chartFile, gitDir)
+	}
+	text, err := g.Command(gitDir, "show", rev+":"+filepath.ToSlash(path))
+	if err != nil {
+		log.Logger().Debugf("no chart %s at revision %s: %v", path, rev, err)
+		return nil, nil
+	}
+	chart := &chartDependencies{}
+	err = yaml.Unmarshal([]byte(text), chart)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal chart %s at revision %s", path, rev)
+	}
+	return chart.Dependencies, nil
+}
+
+// diffChartDependencies returns the dependencies which were added or changed version
+func diffChartDependencies(from, to []ChartDependency) []changelog.DependencyUpdate {
+	fromVersions := map[string]string{}
+	for _, d := range from {
+		fromVersions[d.key()] = d.Version
+	}
+	var answer []changelog.DependencyUpdate
+	for _, d := range to {
+		fromVersion := fromVersions[d.key()]
+		if fromVersion != d.Version {
+			answer = append(answer, changelog.DependencyUpdate{
+				Name:        d.Name,
+				FromVersion: fromVersion,
+				ToVersion:   d.Version,
+			})
+		}
+	}
+	return answer
+}
+
+func (d *ChartDependency) key() string {
+	if d.Alias != "" {
+		return d.Alias
+	}
+	return d.Name
+}
+
+// addDependencyUpdates adds the changes of the chart dependencies which changed version between the two revisions.
+// The changes of each dependency with a local repository are nested into the release
+func (o *Options) addDependencyUpdates(result *changelog.Result, chartFile, gitDir, previousRev, currentRev string) error {
+	release := result.Release
+	fromDeps, err := loadChartDependencies(o.Git(), gitDir, previousRev, chartFile)
+	if err != nil {
+		return err
+	}
+	toDeps, err := loadChartDependencies(o.Git(), gitDir, currentRev, chartFile)
+	if err != nil {
+		return err
+	}
+
+	for _, update := range diffChartDependencies(fromDeps, toDeps) {
+		u := update
+		log.Logger().Infof("dependency %s changed from %s to %s", info(u.Name), info(u.FromVersion), info(u.ToVersion))
+		if release.Annotations == nil {
+			release.Annotations = map[string]string{}
+		}
+		release.Annotations[DependencyAnnotationPrefix+u.Name] = u.FromVersion + ".." + u.ToVersion
+
+		dir := filepath.Join(o.DependenciesDir, u.Name)
+		exists, err := files.DirExists(dir)
+		if err != nil || !exists {
+			log.Logger().Infof("no local repository for dependency %s in %s", u.Name, dir)
+		} else {
+			var diagnostics []changelog.Diagnostic
+			u.Release, diagnostics, err = o.dependencyRelease(dir, &u)
+			result.Diagnostics = append(result.Diagnostics, diagnostics...)
+			if err != nil {
+				log.Logger().Warnf("failed to create the changelog of dependency %s: %v", u.Name, err)
+			}
+			if u.Release != nil {
+				changelog.MergeDependencyRelease(release, u.Name, u.Release)
+			}
+		}
+		result.DependencyUpdates = append(result.DependencyUpdates, u)
+	}
+	return changelog.AddDependencyCommitsAnnotation(result)
+}
+
+// dependencyRelease generates the release of a dependency between the tags of the two versions along with the
+// diagnostics of generating it or else loads the Release YAML of the dependency chart
+func (o *Options) dependencyRelease(dir string, update *changelog.DependencyUpdate) (*v1alpha1.Release, []changelog.Diagnostic, error) {
+	gitDir, _, err := gitclient.FindGitConfigDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if gitDir != "" {
+		fromRev := findVersionTag(o.Git(), gitDir, update.FromVersion)
+		toRev := findVersionTag(o.Git(), gitDir, update.ToVersion)
+		if fromRev != "" && toRev != "" {
+			result, err := o.dependencyGenerator(dir, gitDir, fromRev, toRev).GenerateContext(o.context())
+			if err != nil {
+				return nil, nil, err
+			}
+			return result.Release, result.Diagnostics, nil
+		}
+	}
+
+	chartFile, err := helmhelpers.FindChart(dir)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "could not find helm chart in %s", dir)
+	}
+	releaseFile := filepath.Join(filepath.Dir(chartFile), "templates", o.ReleaseYamlFile)
+	exists, err := files.FileExists(releaseFile)
+	if err != nil || !exists {
+		log.Logger().Infof("no version tags or Release YAML found for dependency %s in %s", update.Name, dir)
+		return nil, nil, nil
+	}
+	data, err := ioutil.ReadFile(releaseFile)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to load file %s", releaseFile)
+	}
+	release, err := loadReleaseYAML(data)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to unmarshal Release YAML file %s", releaseFile)
+	}
+	return release, nil, nil
+}
+
+// loadReleaseYAML unmarshals the Release YAML of a chart, unescaping the text of a Release YAML written for Helm
+// which is recognised by its name being the ReleaseName template
+func loadReleaseYAML(data []byte) (*v1alpha1.Release, error) {
+	release := &v1alpha1.Release{}
+	err := yaml.Unmarshal(data, release)
+	if err != nil {
+		return nil, err
+	}
+	if release.Name != changelog.ReleaseName {
+		return release, nil
+	}
+	release = &v1alpha1.Release{}
+	err = yaml.Unmarshal([]byte(UnescapeHelm(string(data))), release)
+	if err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// dependencyGenerator creates the generator of the changes of a dependency in the given directory which shares the
+// configuration, issue tracker and git provider of the umbrella chart
+func (o *Options) dependencyGenerator(dir, gitDir, fromRev, toRev string) *changelog.Generator {
+	generator := o.newGenerator(gitDir, fromRev, toRev)
+	generator.Owner = ""
+	generator.RepositoryName = ""
+	gitInfo, err := gitdiscovery.FindGitInfoFromDir(dir)
+	if err != nil {
+		log.Logger().Debugf("failed to discover the git repository of %s so not looking up its pull requests: %v", dir, err)
+	} else if gitInfo != nil {
+		generator.Owner = gitInfo.Organisation
+		generator.RepositoryName = gitInfo.Name
+	}
+	return generator
+}
+
+// findVersionTag returns the commit of the tag of the version with or without a 'v' prefix
+func findVersionTag(g gitclient.Interface, dir, version string) string {
+	if version == "" {
+		return ""
+	}
+	for _, tag := range []string{"v" + version, version} {
+		sha, err := g.Command(dir, "rev-parse", "--verify", "--quiet", "refs/tags/"+tag+"^{commit}")
+		if err == nil && sha != "" {
+			return sha
+		}
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/shuttlerock/changlog/pkg/changelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencyReleaseFromReleaseYAML(t *testing.T) {
+	testCases := []struct {
+		name   string
+		noHelm bool
+	}{
+		{name: "escaped for helm"},
+		{name: "without helm", noHelm: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			release := newHelmTextRelease()
+			data, err := MarshalHelmRelease(release)
+			if tc.noHelm {
+				data, err = yaml.Marshal(release)
+			}
+			require.NoError(t, err)
+
+			// a dependency chart without a git repository so its Release YAML is used
+			dir := t.TempDir()
+			templatesDir := filepath.Join(dir, "charts", "sub", "templates")
+			require.NoError(t, os.MkdirAll(templatesDir, 0o755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "charts", "sub", "Chart.yaml"), []byte("apiVersion: v2\nname: sub\nversion: 1.1.0\n"), 0o600))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(templatesDir, "release.yaml"), data, 0o600))
+
+			o := &Options{ReleaseYamlFile: "release.yaml"}
+			loaded, diagnostics, err := o.dependencyRelease(dir, &changelog.DependencyUpdate{Name: "sub", FromVersion: "1.0.0", ToVersion: "1.1.0"})
+			require.NoError(t, err)
+			assert.Empty(t, diagnostics)
+			require.NotNil(t, loaded)
+			assert.Equal(t, release.Spec, loaded.Spec, "the commit messages are as they were before they were escaped")
+		})
+	}
+}
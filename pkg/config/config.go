@@ -0,0 +1,207 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx-helpers/v3/pkg/files"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultConfigFile the name of the changelog configuration file in the root of a repository
+	DefaultConfigFile = ".changelog.yaml"
+
+	// DefaultEpicField the id of the epic link field in Jira Cloud
+	DefaultEpicField = "customfield_10014"
+
+	// DefaultSprintField the id of the sprint field in Jira Cloud
+	DefaultSprintField = "customfield_10020"
+)
+
+var (
+	// DefaultLabelGroups the sections used when grouping by pull request labels if none are configured
+	DefaultLabelGroups = []LabelGroup{
+		{Title: "Security", Labels: []string{"security"}, Priority: 30},
+		{Title: "New Features", Labels: []string{"enhancement", "feature"}, Priority: 10},
+		{Title: "Bug Fixes", Labels: []string{"bug"}, Priority: 20},
+	}
+
+	// DefaultSkipLabels the pull request labels which drop an entry from the changelog if none are configured
+	DefaultSkipLabels = []string{"skip-changelog"}
+
+	// DefaultIssueTypeGroups the sections used when grouping by issue type if none are configured
+	DefaultIssueTypeGroups = []IssueTypeGroup{
+		{Title: "New Features", Types: []string{"Story", "Feature", "New Feature", "Epic"}},
+		{Title: "Bug Fixes", Types: []string{"Bug", "Defect"}},
+		{Title: "Improvements", Types: []string{"Improvement"}},
+		{Title: "Tasks", Types: []string{"Task", "Sub-task", "Subtask"}},
+	}
+
+	// DefaultBots the regular expression matching the login, name or email of bot accounts if none is configured
+	DefaultBots = `(?i)\[bot\]|^(dependabot|renovate|github-actions|jenkins-x-bot)\b|^github$|noreply@github\.com$`
+)
+
+// Config the configuration of the changelog generation
+type Config struct {
+	// LabelGroups maps pull request labels to sections of the changelog
+	LabelGroups []LabelGroup `json:"labelGroups,omitempty"`
+
+	// SkipLabels pull requests with any of these labels are left out of the changelog
+	SkipLabels []string `json:"skipLabels,omitempty"`
+
+	// Exclude rules which drop commits from the release and the changelog
+	Exclude []ExclusionRule `json:"exclude,omitempty"`
+
+	// Charts the source paths of the charts in a repository with more than one chart
+	Charts []ChartSources `json:"charts,omitempty"`
+
+	// Bots a regular expression matching the login, name or email of the bot accounts which are left out of the
+	// contributors
+	Bots string `json:"bots,omitempty"`
+
+	// Teams maps the authors of commits to the teams they belong to
+	Teams []Team `json:"teams,omitempty"`
+
+	// CodeOwners the CODEOWNERS file relative to the root of the repository used to find the teams of the commits
+	// whose authors are not members of any team. Defaults to CODEOWNERS in the root, .github or docs directory
+	CodeOwners string `json:"codeOwners,omitempty"`
+
+	// IssueFields the Jira fields of the issues added to the release
+	IssueFields IssueFields `json:"issueFields,omitempty"`
+
+	// IssueTypeGroups maps issue types to sections of the changelog when grouping by issue type
+	IssueTypeGroups []IssueTypeGroup `json:"issueTypeGroups,omitempty"`
+
+	// IssueProjects the keys of the issue tracker projects such as ABC. Unknown keys of other projects such as
+	// UTF-8 are not missing issues in strict mode. Defaults to the project of the issue tracker if any
+	IssueProjects []string `json:"issueProjects,omitempty"`
+}
+
+// IssueFields the ids of the Jira fields which differ between Jira instances
+type IssueFields struct {
+	// Epic the id of the epic link field. Defaults to customfield_10014
+	Epic string `json:"epic,omitempty"`
+
+	// Sprint the id of the sprint field. Defaults to customfield_10020
+	Sprint string `json:"sprint,omitempty"`
+
+	// Custom the other fields to add such as story points
+	Custom []CustomField `json:"custom,omitempty"`
+}
+
+// CustomField a Jira custom field added to the issues of the release
+type CustomField struct {
+	// Name the name of the field in the release such as story-points
+	Name string `json:"name"`
+
+	// Field the id of the field in Jira such as customfield_10016
+	Field string `json:"field"`
+}
+
+// IssueTypeGroup a section of the changelog containing the commits of issues of any of the types
+type IssueTypeGroup struct {
+	// Title the title of the section
+	Title string `json:"title"`
+
+	// Types the issue types such as Bug which belong in this section
+	Types []string `json:"types"`
+}
+
+// Team a team whose members author commits
+type Team struct {
+	// Name the name of the team
+	Name string `json:"name"`
+
+	// Members the logins or emails of the members of the team
+	Members []string `json:"members,omitempty"`
+}
+
+// ChartSources the paths in the repository containing the source code of a chart
+type ChartSources struct {
+	// Name the name of the chart
+	Name string `json:"name"`
+
+	// Paths the directories or files relative to the root of the repository. The chart directory is always included
+	Paths []string `json:"paths"`
+}
+
+// LabelGroup a section of the changelog containing the pull requests with any of the labels
+type LabelGroup struct {
+	// Title the title of the section
+	Title string `json:"title"`
+
+	// Labels the pull request labels which belong in this section
+	Labels []string `json:"labels"`
+
+	// Priority decides the section of a pull request with labels from more than one section.
+	// The highest priority wins, then the first section in the list
+	Priority int `json:"priority,omitempty"`
+}
+
+// ExclusionRule drops the commits matching all of the specified conditions
+type ExclusionRule struct {
+	// Name describes the rule when listing the excluded commits
+	Name string `json:"name,omitempty"`
+
+	// Subject a regular expression matched against the first line of the commit message
+	Subject string `json:"subject,omitempty"`
+
+	// Author a regular expression matched against the name or email of the commit author such as 'dependabot\[bot\]'
+	Author string `json:"author,omitempty"`
+
+	// Trailer text such as '[skip changelog]' found anywhere in the commit message ignoring case
+	Trailer string `json:"trailer,omitempty"`
+
+	// Types the conventional commit types such as 'chore' or 'test'
+	Types []string `json:"types,omitempty"`
+}
+
+// LoadConfig loads the configuration from the given file, if the file does not exist the defaults are returned
+func LoadConfig(file string) (*Config, error) {
+	config := &Config{}
+	exists, err := files.FileExists(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if file exists %s", file)
+	}
+	if exists {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load file %s", file)
+		}
+		err = yaml.Unmarshal(data, config)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal YAML file %s", file)
+		}
+	}
+	config.Defaults()
+	return config, nil
+}
+
+// LoadConfigFromDir loads the default configuration file from the given directory
+func LoadConfigFromDir(dir string) (*Config, error) {
+	return LoadConfig(filepath.Join(dir, DefaultConfigFile))
+}
+
+// Defaults sets the default values of any settings which are not configured
+func (c *Config) Defaults() {
+	if len(c.LabelGroups) == 0 {
+		c.LabelGroups = DefaultLabelGroups
+	}
+	if c.SkipLabels == nil {
+		c.SkipLabels = DefaultSkipLabels
+	}
+	if c.Bots == "" {
+		c.Bots = DefaultBots
+	}
+	if len(c.IssueTypeGroups) == 0 {
+		c.IssueTypeGroups = DefaultIssueTypeGroups
+	}
+	if c.IssueFields.Epic == "" {
+		c.IssueFields.Epic = DefaultEpicField
+	}
+	if c.IssueFields.Sprint == "" {
+		c.IssueFields.Sprint = DefaultSprintField
+	}
+}
@@ -0,0 +1,82 @@
+// Package gittest creates git repositories with known commits for tests
+package gittest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx-helpers/v3/pkg/gitclient"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// AuthorName the name of the author of the commits
+	AuthorName = "Jane Doe"
+
+	// AuthorEmail the email of the author of the commits
+	AuthorEmail = "jane@example.com"
+
+	// FirstCommitTime the unix time of the first commit. Each following commit is a minute later so that the commits
+	// and tags have a well defined order by date
+	FirstCommitTime = 1600000000
+)
+
+// Commit a commit of a test repository
+type Commit struct {
+	// Message the commit message
+	Message string
+
+	// Files the contents of the files written before the commit indexed by their path. The commit is empty if none
+	Files map[string]string
+
+	// Tag the tag of the commit if any
+	Tag string
+}
+
+// CreateRepository creates a git repository with the commits, which are a minute apart, and returns the directory of
+// the repository and the SHA of each commit
+func CreateRepository(t *testing.T, g gitclient.Interface, commits ...Commit) (string, []string) {
+	dir := t.TempDir()
+	_, err := g.Command(dir, "init", "-q")
+	require.NoError(t, err)
+	var shas []string
+	for i, commit := range commits {
+		date := fmt.Sprintf("%d +0000", FirstCommitTime+i*60)
+		t.Setenv("GIT_AUTHOR_DATE", date)
+		t.Setenv("GIT_COMMITTER_DATE", date)
+		for name, text := range commit.Files {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+			require.NoError(t, ioutil.WriteFile(path, []byte(text), 0o600))
+		}
+		_, err = g.Command(dir, "add", "-A")
+		require.NoError(t, err)
+		Run(t, g, dir, "commit", "-q", "--allow-empty", "-m", commit.Message)
+		shas = append(shas, Run(t, g, dir, "rev-parse", "HEAD"))
+		if commit.Tag != "" {
+			_, err = g.Command(dir, "tag", commit.Tag)
+			require.NoError(t, err)
+		}
+	}
+	return dir, shas
+}
+
+// Run runs the git command in the repository as the author of the commits and returns its output
+func Run(t *testing.T, g gitclient.Interface, dir string, args ...string) string {
+	args = append([]string{"-c", "user.name=" + AuthorName, "-c", "user.email=" + AuthorEmail}, args...)
+	out, err := g.Command(dir, args...)
+	require.NoError(t, err)
+	return out
+}
+
+// Messages returns the commits of the messages
+func Messages(messages ...string) []Commit {
+	var answer []Commit
+	for _, message := range messages {
+		answer = append(answer, Commit{Message: message})
+	}
+	return answer
+}
@@ -0,0 +1,267 @@
+package issuefile
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/tracker"
+)
+
+// Issue an issue in the issues file
+type Issue struct {
+	// Key the key of the issue such as ABC-123 which is referenced in commit messages
+	Key string `json:"key"`
+
+	// Title the title of the issue
+	Title string `json:"title,omitempty"`
+
+	// Body the description of the issue
+	Body string `json:"body,omitempty"`
+
+	// State the state of the issue such as open, closed or done
+	State string `json:"state,omitempty"`
+
+	// URL the link to the issue. Defaults to the key appended to the URL of the issues file
+	URL string `json:"url,omitempty"`
+
+	// Labels the labels of the issue
+	Labels []string `json:"labels,omitempty"`
+
+	// Assignees the logins of the users the issue is assigned to
+	Assignees []string `json:"assignees,omitempty"`
+
+	// Author the login of the user who created the issue
+	Author string `json:"author,omitempty"`
+
+	// Closed whether the issue is closed
+	Closed bool `json:"closed,omitempty"`
+
+	// Created when the issue was created
+	Created *time.Time `json:"created,omitempty"`
+
+	// Updated when the issue was last updated, which is used as the closing time of a closed issue
+	Updated *time.Time `json:"updated,omitempty"`
+
+	// Type the issue type such as Bug or Story
+	Type string `json:"type,omitempty"`
+
+	// Priority the priority of the issue such as High
+	Priority string `json:"priority,omitempty"`
+
+	// Components the components of the issue
+	Components []string `json:"components,omitempty"`
+
+	// FixVersions the versions the issue is fixed in
+	FixVersions []string `json:"fixVersions,omitempty"`
+
+	// Epic the key of the epic of the issue
+	Epic string `json:"epic,omitempty"`
+
+	// Parent the key of the parent of a sub-task
+	Parent string `json:"parent,omitempty"`
+
+	// Sprint the name of the sprint of the issue
+	Sprint string `json:"sprint,omitempty"`
+
+	// Fields any other fields such as story points indexed by their name
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// File the issues file which is either a list of issues or an object with the base URL of the issues
+type File struct {
+	// URL the home URL of the issue tracker the issue links are relative to
+	URL string `json:"url,omitempty"`
+
+	// Issues the issues
+	Issues []Issue `json:"issues,omitempty"`
+}
+
+// Provider an issue provider which looks up the issues in a YAML or JSON file so that no issue tracker is called
+type Provider struct {
+	// FileName the file the issues were loaded from
+	FileName string
+
+	// File the contents of the issues file
+	File File
+
+	issues map[string]*Issue
+}
+
+// NewProvider loads the issue provider from the YAML or JSON issues file
+func NewProvider(fileName string) (*Provider, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load issues file %s", fileName)
+	}
+	file := File{}
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("-")) || bytes.HasPrefix(trimmed, []byte("[")) {
+		err = yaml.Unmarshal(data, &file.Issues)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal issues file %s", fileName)
+	}
+	return NewProviderFromFile(fileName, file)
+}
+
+// NewProviderFromFile creates the issue provider for the issues
+func NewProviderFromFile(fileName string, file File) (*Provider, error) {
+	p := &Provider{
+		FileName: fileName,
+		File:     file,
+		issues:   map[string]*Issue{},
+	}
+	for k := range file.Issues {
+		issue := &file.Issues[k]
+		if issue.Key == "" {
+			return nil, errors.Errorf("issue %d in issues file %s has no key", k+1, fileName)
+		}
+		key := strings.ToUpper(issue.Key)
+		if _, ok := p.issues[key]; ok {
+			return nil, errors.Errorf("duplicate issue %s in issues file %s", issue.Key, fileName)
+		}
+		p.issues[key] = issue
+	}
+	return p, nil
+}
+
+// GetIssue returns the issue of the key or nil if it is not in the file
+func (p *Provider) GetIssue(key string) (*scm.Issue, error) {
+	issue := p.issues[strings.ToUpper(key)]
+	if issue == nil {
+		return nil, nil
+	}
+	return p.toScmIssue(issue), nil
+}
+
+// GetIssueDetails returns the type, epic and other fields of the issue or nil if it is not in the file
+func (p *Provider) GetIssueDetails(key string) (*tracker.Details, error) {
+	issue := p.issues[strings.ToUpper(key)]
+	if issue == nil {
+		return nil, nil
+	}
+	return &tracker.Details{
+		Type:        issue.Type,
+		Status:      issue.State,
+		Priority:    issue.Priority,
+		Components:  issue.Components,
+		FixVersions: issue.FixVersions,
+		Epic:        issue.Epic,
+		Parent:      issue.Parent,
+		Sprint:      issue.Sprint,
+		Fields:      issue.Fields,
+	}, nil
+}
+
+// SearchIssues returns the open issues whose key or title contains the query
+func (p *Provider) SearchIssues(query string) ([]*scm.Issue, error) {
+	query = strings.ToLower(query)
+	var answer []*scm.Issue
+	for k := range p.File.Issues {
+		issue := &p.File.Issues[k]
+		if issue.isClosed() {
+			continue
+		}
+		if strings.Contains(strings.ToLower(issue.Key), query) || strings.Contains(strings.ToLower(issue.Title), query) {
+			answer = append(answer, p.toScmIssue(issue))
+		}
+	}
+	return answer, nil
+}
+
+// SearchIssuesClosedSince returns the closed issues last updated after the given time
+func (p *Provider) SearchIssuesClosedSince(t time.Time) ([]*scm.Issue, error) {
+	var answer []*scm.Issue
+	for k := range p.File.Issues {
+		issue := &p.File.Issues[k]
+		if issue.isClosed() && issue.Updated != nil && issue.Updated.After(t) {
+			answer = append(answer, p.toScmIssue(issue))
+		}
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Updated.Before(answer[j].Updated)
+	})
+	return answer, nil
+}
+
+// CreateIssue is not supported as the issues file is read only
+func (p *Provider) CreateIssue(issue *scm.Issue) (*scm.Issue, error) {
+	return nil, errors.Errorf("cannot create issue %s in read only issues file %s", issue.Title, p.FileName)
+}
+
+// CreateIssueComment is not supported as the issues file is read only
+func (p *Provider) CreateIssueComment(key string, comment string) error {
+	return errors.Errorf("cannot comment on issue %s in read only issues file %s", key, p.FileName)
+}
+
+// IssueURL returns the URL of the issue from the file or else the key appended to the home URL
+func (p *Provider) IssueURL(key string) string {
+	issue := p.issues[strings.ToUpper(key)]
+	if issue != nil && issue.URL != "" {
+		return issue.URL
+	}
+	if p.File.URL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(p.File.URL, "/") + "/" + key
+}
+
+// HomeURL returns the URL of the issue tracker or else the issues file
+func (p *Provider) HomeURL() string {
+	if p.File.URL != "" {
+		return p.File.URL
+	}
+	return p.FileName
+}
+
+func (p *Provider) toScmIssue(issue *Issue) *scm.Issue {
+	answer := &scm.Issue{
+		Title:  issue.Title,
+		Body:   issue.Body,
+		Link:   p.IssueURL(issue.Key),
+		State:  issue.State,
+		Labels: issue.Labels,
+		Closed: issue.isClosed(),
+		Author: toScmUser(issue.Author),
+	}
+	for _, login := range issue.Assignees {
+		answer.Assignees = append(answer.Assignees, toScmUser(login))
+	}
+	if answer.Assignees == nil {
+		answer.Assignees = []scm.User{}
+	}
+	if issue.Created != nil {
+		answer.Created = *issue.Created
+	}
+	if issue.Updated != nil {
+		answer.Updated = *issue.Updated
+	}
+	return answer
+}
+
+// isClosed returns true if the issue is marked as closed or its state is a closed one
+func (i *Issue) isClosed() bool {
+	if i.Closed {
+		return true
+	}
+	switch strings.ToLower(i.State) {
+	case "closed", "done", "resolved", "merged":
+		return true
+	}
+	return false
+}
+
+func toScmUser(login string) scm.User {
+	return scm.User{
+		Login: login,
+		Name:  login,
+	}
+}
@@ -0,0 +1,109 @@
+package issuefile_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/shuttlerock/changlog/pkg/issuefile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeIssuesFile writes the issues file into a temporary directory and returns its path
+func writeIssuesFile(t *testing.T, name, text string) string {
+	fileName := filepath.Join(t.TempDir(), name)
+	require.NoError(t, ioutil.WriteFile(fileName, []byte(text), 0o600))
+	return fileName
+}
+
+func TestProviderLookup(t *testing.T) {
+	testCases := []struct {
+		name string
+		file string
+		text string
+		url  string
+	}{
+		{
+			name: "object with a URL",
+			file: "issues.yaml",
+			text: `url: https://jira.example.com/browse/
+issues:
+- key: ABC-1
+  title: Add the issues view
+  state: done
+  type: Story
+  epic: ABC-100
+`,
+			url: "https://jira.example.com/browse/ABC-1",
+		},
+		{
+			name: "list of issues",
+			file: "issues.json",
+			text: `[{"key": "abc-1", "title": "Add the issues view", "state": "done", "type": "Story", "epic": "ABC-100", "url": "https://issues.example.com/1"}]`,
+			url:  "https://issues.example.com/1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := issuefile.NewProvider(writeIssuesFile(t, tc.file, tc.text))
+			require.NoError(t, err)
+
+			issue, err := p.GetIssue("ABC-1")
+			require.NoError(t, err)
+			require.NotNil(t, issue)
+			assert.Equal(t, "Add the issues view", issue.Title)
+			assert.Equal(t, tc.url, issue.Link)
+			assert.True(t, issue.Closed)
+
+			details, err := p.GetIssueDetails("abc-1")
+			require.NoError(t, err)
+			require.NotNil(t, details)
+			assert.Equal(t, "Story", details.Type)
+			assert.Equal(t, "ABC-100", details.Epic)
+
+			// keys which are not in the file such as UTF-8 are not found rather than failing
+			issue, err = p.GetIssue("UTF-8")
+			require.NoError(t, err)
+			assert.Nil(t, issue)
+			details, err = p.GetIssueDetails("UTF-8")
+			require.NoError(t, err)
+			assert.Nil(t, details)
+		})
+	}
+}
+
+func TestNewProviderInvalidFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "malformed YAML",
+			text:     "issues:\n- key: ABC-1\n  title: [unterminated\n",
+			expected: "failed to unmarshal issues file",
+		},
+		{
+			name:     "issue without a key",
+			text:     "issues:\n- title: No key\n",
+			expected: "issue 1 in issues file",
+		},
+		{
+			name:     "duplicate issues ignoring case",
+			text:     "- key: ABC-1\n- key: abc-1\n",
+			expected: "duplicate issue abc-1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := issuefile.NewProvider(writeIssuesFile(t, "issues.yaml", tc.text))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+
+	_, err := issuefile.NewProvider(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load issues file")
+}
@@ -0,0 +1,149 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/jx-logging/v3/pkg/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultTimeout the default timeout of each call to the git provider or issue tracker
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultMaxRetries the default number of times a failed call is retried
+	DefaultMaxRetries = 3
+
+	// DefaultInitialInterval the default delay before the first retry
+	DefaultInitialInterval = 500 * time.Millisecond
+)
+
+// retryableStatusRegex matches the status codes worth retrying in the error messages of clients which do not
+// return the response such as the Jira client
+var retryableStatusRegex = regexp.MustCompile(`(?i)status(?: code)?:? (429|5\d\d)\b`)
+
+// Options how calls to the git provider and issue tracker are retried
+type Options struct {
+	// Timeout the timeout of each call. There is no timeout if zero
+	Timeout time.Duration
+
+	// MaxRetries the maximum number of times a failed call is retried. A call is not retried if zero
+	MaxRetries int
+
+	// InitialInterval the delay before the first retry which roughly doubles for each retry.
+	// Defaults to DefaultInitialInterval
+	InitialInterval time.Duration
+}
+
+// Do calls the function with a context limited by the per call timeout, retrying with exponential backoff while
+// it fails with a retryable error and the parent context is not done
+func Do(ctx context.Context, o Options, fn func(ctx context.Context) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var policy backoff.BackOff = &backoff.StopBackOff{}
+	if o.MaxRetries > 0 {
+		exponential := backoff.NewExponentialBackOff()
+		exponential.InitialInterval = o.InitialInterval
+		if exponential.InitialInterval <= 0 {
+			exponential.InitialInterval = DefaultInitialInterval
+		}
+		exponential.MaxElapsedTime = 0
+		policy = backoff.WithMaxRetries(exponential, uint64(o.MaxRetries))
+	}
+
+	operation := func() error {
+		if ctx.Err() != nil {
+			return backoff.Permanent(ctx.Err())
+		}
+		callCtx := ctx
+		if o.Timeout > 0 {
+			var cancel context.CancelFunc
+			callCtx, cancel = context.WithTimeout(ctx, o.Timeout)
+			defer cancel()
+		}
+		err := fn(callCtx)
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(*backoff.PermanentError); ok {
+			return err
+		}
+		if !IsRetryable(err) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+	notify := func(err error, next time.Duration) {
+		log.Logger().Debugf("retrying in %s after: %v", next.Round(time.Millisecond), err)
+	}
+	return backoff.RetryNotify(operation, backoff.WithContext(policy, ctx), notify)
+}
+
+// Await calls the function which does not support a context in the background returning the context error if
+// the context is done before the function returns. The function cannot be stopped so it keeps running in its
+// goroutine until it returns and its result is discarded, so pass the context to the call instead where the client
+// supports one
+func Await(ctx context.Context, fn func() error) error {
+	ch := make(chan error, 1)
+	go func() {
+		ch <- fn()
+	}()
+	select {
+	case err := <-ch:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Permanent marks the error as not worth retrying
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return backoff.Permanent(err)
+}
+
+// ScmError returns the error of a git provider call, marking it as permanent if the response status shows that
+// retrying would not help
+func ScmError(res *scm.Response, err error) error {
+	if err == nil || res == nil || res.Status == 0 {
+		return err
+	}
+	if IsRetryableStatus(res.Status) {
+		return err
+	}
+	return Permanent(err)
+}
+
+// IsRetryableStatus returns true if the HTTP status is for a rate limit or a server error
+func IsRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// IsRetryable returns true if the error is a timeout, a network error or a rate limit or server error response
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	cause := errors.Cause(err)
+	if cause == context.Canceled {
+		return false
+	}
+	if cause == context.DeadlineExceeded || cause == io.EOF || cause == io.ErrUnexpectedEOF {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return retryableStatusRegex.MatchString(err.Error())
+}
@@ -0,0 +1,241 @@
+package retry_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo(t *testing.T) {
+	serverError := errors.New("unexpected status code: 503")
+	notFound := errors.New("unexpected status code: 404")
+	testCases := []struct {
+		name     string
+		retries  int
+		errs     []error
+		expected error
+		calls    int
+	}{
+		{
+			name:  "success",
+			calls: 1,
+		},
+		{
+			name:    "retryable errors until success",
+			retries: 3,
+			errs:    []error{serverError, io.EOF},
+			calls:   3,
+		},
+		{
+			name:     "retries exhausted",
+			retries:  2,
+			errs:     []error{serverError, serverError, serverError, serverError},
+			expected: serverError,
+			calls:    3,
+		},
+		{
+			name:     "not retried without retries",
+			errs:     []error{serverError},
+			expected: serverError,
+			calls:    1,
+		},
+		{
+			name:     "error which is not retryable",
+			retries:  3,
+			errs:     []error{notFound},
+			expected: notFound,
+			calls:    1,
+		},
+		{
+			name:     "permanent error",
+			retries:  3,
+			errs:     []error{retry.Permanent(serverError)},
+			expected: serverError,
+			calls:    1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := retry.Options{MaxRetries: tc.retries, InitialInterval: time.Millisecond}
+			calls := 0
+			err := retry.Do(context.Background(), o, func(ctx context.Context) error {
+				calls++
+				if calls <= len(tc.errs) {
+					return tc.errs[calls-1]
+				}
+				return nil
+			})
+			assert.Equal(t, tc.expected, err)
+			assert.Equal(t, tc.calls, calls)
+		})
+	}
+}
+
+func TestDoTimeout(t *testing.T) {
+	o := retry.Options{Timeout: 10 * time.Millisecond, MaxRetries: 1, InitialInterval: time.Millisecond}
+	calls := 0
+	err := retry.Do(context.Background(), o, func(ctx context.Context) error {
+		calls++
+		_, ok := ctx.Deadline()
+		require.True(t, ok, "each call has the timeout")
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 2, calls, "a call which timed out is retried")
+}
+
+func TestDoCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	err := retry.Do(ctx, retry.Options{MaxRetries: 3}, func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, calls, "nothing is called once the context is done")
+}
+
+func TestAwait(t *testing.T) {
+	err := retry.Await(context.Background(), func() error {
+		return io.EOF
+	})
+	assert.Equal(t, io.EOF, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	err = retry.Await(ctx, func() error {
+		<-done
+		return nil
+	})
+	close(done)
+	assert.Equal(t, context.DeadlineExceeded, err, "the context error is returned without waiting for the function")
+}
+
+func TestScmError(t *testing.T) {
+	failed := errors.New("failed")
+	testCases := []struct {
+		name      string
+		res       *scm.Response
+		err       error
+		permanent bool
+	}{
+		{
+			name: "no error",
+			res:  &scm.Response{Status: http.StatusOK},
+		},
+		{
+			name: "no response",
+			err:  failed,
+		},
+		{
+			name: "no status",
+			res:  &scm.Response{},
+			err:  failed,
+		},
+		{
+			name: "rate limited",
+			res:  &scm.Response{Status: http.StatusTooManyRequests},
+			err:  failed,
+		},
+		{
+			name: "server error",
+			res:  &scm.Response{Status: http.StatusBadGateway},
+			err:  failed,
+		},
+		{
+			name:      "not found",
+			res:       &scm.Response{Status: http.StatusNotFound},
+			err:       failed,
+			permanent: true,
+		},
+		{
+			name:      "unauthorized",
+			res:       &scm.Response{Status: http.StatusUnauthorized},
+			err:       failed,
+			permanent: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := retry.ScmError(tc.res, tc.err)
+			if tc.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			permanent, ok := err.(*backoff.PermanentError)
+			assert.Equal(t, tc.permanent, ok)
+			if ok {
+				assert.Equal(t, tc.err, permanent.Err)
+			} else {
+				assert.Equal(t, tc.err, err)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name: "cancelled",
+			err:  errors.Wrap(context.Canceled, "failed to get issue"),
+		},
+		{
+			name:     "deadline exceeded",
+			err:      errors.Wrap(context.DeadlineExceeded, "failed to get issue"),
+			expected: true,
+		},
+		{
+			name:     "connection closed",
+			err:      io.ErrUnexpectedEOF,
+			expected: true,
+		},
+		{
+			name:     "network error",
+			err:      errors.Wrap(&net.DNSError{Err: "no such host", Name: "jira.example.com"}, "failed to get issue"),
+			expected: true,
+		},
+		{
+			name:     "rate limit in the message",
+			err:      errors.New("request failed. Please analyze the request body for more details. Status code: 429"),
+			expected: true,
+		},
+		{
+			name:     "server error in the message",
+			err:      errors.New("unexpected status: 502 Bad Gateway"),
+			expected: true,
+		},
+		{
+			name: "not found in the message",
+			err:  errors.New("request failed. Please analyze the request body for more details. Status code: 404"),
+		},
+		{
+			name: "other error",
+			err:  errors.New("issue ABC-1 does not exist"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, retry.IsRetryable(tc.err))
+		})
+	}
+}
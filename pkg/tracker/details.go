@@ -0,0 +1,110 @@
+package tracker
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// Details the issue tracker fields of an issue which the issue provider does not return such as the issue type
+type Details struct {
+	// Type the issue type such as Bug or Story
+	Type string `json:"type,omitempty"`
+
+	// Status the status of the issue such as In Progress or Done
+	Status string `json:"status,omitempty"`
+
+	// Priority the priority of the issue such as High
+	Priority string `json:"priority,omitempty"`
+
+	// Components the components of the issue
+	Components []string `json:"components,omitempty"`
+
+	// FixVersions the versions the issue is fixed in
+	FixVersions []string `json:"fixVersions,omitempty"`
+
+	// Epic the key of the epic of the issue
+	Epic string `json:"epic,omitempty"`
+
+	// EpicName the name of the epic of the issue if known
+	EpicName string `json:"epicName,omitempty"`
+
+	// Parent the key of the parent of a sub-task
+	Parent string `json:"parent,omitempty"`
+
+	// Sprint the name of the latest sprint of the issue
+	Sprint string `json:"sprint,omitempty"`
+
+	// Fields the configured custom fields such as story points indexed by their name
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// DetailsProvider an issue provider which can return the details of an issue
+type DetailsProvider interface {
+	// GetIssueDetails returns the details of the issue or nil if it is not known
+	GetIssueDetails(key string) (*Details, error)
+}
+
+// ContextDetailsProvider a details provider which stops looking up the details of an issue once the context is done
+type ContextDetailsProvider interface {
+	// GetIssueDetailsContext returns the details of the issue or nil if it is not known
+	GetIssueDetailsContext(ctx context.Context, key string) (*Details, error)
+}
+
+// Labels returns the details as labels of the form name:value such as type:Bug
+func (d *Details) Labels() []string {
+	if d == nil {
+		return nil
+	}
+	var answer []string
+	add := func(name, value string) {
+		if value != "" {
+			answer = append(answer, name+":"+value)
+		}
+	}
+	add("type", d.Type)
+	add("priority", d.Priority)
+	for _, c := range d.Components {
+		add("component", c)
+	}
+	for _, v := range d.FixVersions {
+		add("fixVersion", v)
+	}
+	add("epic", d.Epic)
+	add("parent", d.Parent)
+	add("sprint", d.Sprint)
+
+	names := make([]string, 0, len(d.Fields))
+	for name := range d.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(name, d.Fields[name])
+	}
+	return answer
+}
+
+// EpicKey returns the epic of the issue or else its parent
+func (d *Details) EpicKey() string {
+	if d == nil {
+		return ""
+	}
+	if d.Epic != "" {
+		return d.Epic
+	}
+	return d.Parent
+}
+
+// IsType returns true if the issue type is any of the types ignoring case
+func (d *Details) IsType(types ...string) bool {
+	if d == nil || d.Type == "" {
+		return false
+	}
+	for _, t := range types {
+		if strings.EqualFold(strings.TrimSpace(t), d.Type) {
+			return true
+		}
+	}
+	return false
+}
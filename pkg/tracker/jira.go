@@ -0,0 +1,166 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/jenkins-x-plugins/jx-changelog/pkg/issues"
+	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/config"
+)
+
+// legacySprintName precedes the name in the legacy string representation of a sprint returned by older Jira servers
+const legacySprintName = "name="
+
+// JiraProvider a Jira issue provider which also returns the issue type, status and the configured fields of the
+// issues
+type JiraProvider struct {
+	*issues.JiraService
+
+	// Fields the ids of the epic, sprint and custom fields
+	Fields config.IssueFields
+
+	lock    sync.Mutex
+	details map[string]*Details
+}
+
+// NewJiraProvider creates a Jira issue provider returning the details of the issues
+func NewJiraProvider(service *issues.JiraService, fields config.IssueFields) *JiraProvider {
+	return &JiraProvider{
+		JiraService: service,
+		Fields:      fields,
+		details:     map[string]*Details{},
+	}
+}
+
+// GetIssueDetails returns the details of the issue fetching it if it has not been fetched yet. The issue itself is
+// still returned by GetIssue of the embedded JiraService so that it is converted the same way as without details
+func (p *JiraProvider) GetIssueDetails(key string) (*Details, error) {
+	return p.GetIssueDetailsContext(context.Background(), key)
+}
+
+// GetIssueDetailsContext returns the details of the issue fetching it with the context if it has not been fetched
+// yet
+func (p *JiraProvider) GetIssueDetailsContext(ctx context.Context, key string) (*Details, error) {
+	p.lock.Lock()
+	details := p.details[strings.ToUpper(key)]
+	p.lock.Unlock()
+	if details != nil {
+		return details, nil
+	}
+	issue, _, err := p.JiraClient.Issue.GetWithContext(ctx, key, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get Jira issue %s", key)
+	}
+	details = p.toDetails(issue)
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.details[strings.ToUpper(key)] = details
+	return details, nil
+}
+
+func (p *JiraProvider) toDetails(issue *jira.Issue) *Details {
+	answer := &Details{}
+	fields := issue.Fields
+	if fields == nil {
+		return answer
+	}
+	answer.Type = fields.Type.Name
+	if fields.Status != nil {
+		answer.Status = fields.Status.Name
+	}
+	if fields.Priority != nil {
+		answer.Priority = fields.Priority.Name
+	}
+	for _, c := range fields.Components {
+		if c != nil && c.Name != "" {
+			answer.Components = append(answer.Components, c.Name)
+		}
+	}
+	for _, v := range fields.FixVersions {
+		if v != nil && v.Name != "" {
+			answer.FixVersions = append(answer.FixVersions, v.Name)
+		}
+	}
+	if fields.Epic != nil {
+		answer.Epic = fields.Epic.Key
+		answer.EpicName = fields.Epic.Name
+	}
+	if answer.Epic == "" && p.Fields.Epic != "" {
+		answer.Epic = fieldText(fields.Unknowns[p.Fields.Epic])
+	}
+	if fields.Parent != nil {
+		answer.Parent = fields.Parent.Key
+	}
+	if fields.Sprint != nil {
+		answer.Sprint = fields.Sprint.Name
+	}
+	if answer.Sprint == "" && p.Fields.Sprint != "" {
+		answer.Sprint = sprintName(fields.Unknowns[p.Fields.Sprint])
+	}
+	for _, f := range p.Fields.Custom {
+		value := fieldText(fields.Unknowns[f.Field])
+		if value == "" {
+			continue
+		}
+		if answer.Fields == nil {
+			answer.Fields = map[string]string{}
+		}
+		answer.Fields[f.Name] = value
+	}
+	return answer
+}
+
+// sprintName returns the name of the latest sprint of the sprint field which is a list of sprints
+func sprintName(value interface{}) string {
+	if list, ok := value.([]interface{}); ok {
+		if len(list) == 0 {
+			return ""
+		}
+		value = list[len(list)-1]
+	}
+	text := fieldText(value)
+	if idx := strings.Index(text, legacySprintName); idx >= 0 && strings.HasPrefix(text, "com.atlassian") {
+		text = text[idx+len(legacySprintName):]
+		if end := strings.Index(text, ","); end >= 0 {
+			text = text[:end]
+		}
+	}
+	return text
+}
+
+// fieldText returns the text of a custom field value which is either a simple value, an object with a value, name
+// or key or a list of those
+func fieldText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]interface{}:
+		for _, name := range []string{"value", "name", "key", "displayName"} {
+			if text := fieldText(v[name]); text != "" {
+				return text
+			}
+		}
+		return ""
+	case []interface{}:
+		var texts []string
+		for _, item := range v {
+			if text := fieldText(item); text != "" {
+				texts = append(texts, text)
+			}
+		}
+		return strings.Join(texts, ",")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
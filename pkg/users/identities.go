@@ -0,0 +1,115 @@
+package users
+
+import (
+	"strings"
+
+	"github.com/shuttlerock/devops-api/api/v1alpha1"
+)
+
+// Identities merges the partial details of the same person from the git provider, the issue tracker and the git
+// commits into one canonical record. Details are for the same person if they share a login or an email
+type Identities struct {
+	people  []*v1alpha1.UserDetails
+	byLogin map[string]int
+	byEmail map[string]int
+}
+
+// NewIdentities creates an empty set of identities
+func NewIdentities() *Identities {
+	return &Identities{
+		byLogin: map[string]int{},
+		byEmail: map[string]int{},
+	}
+}
+
+// Add merges the details into the canonical record of the person, joining the records which turn out to be for the
+// same person, and returns the canonical record
+func (i *Identities) Add(u *v1alpha1.UserDetails) *v1alpha1.UserDetails {
+	if u == nil {
+		return nil
+	}
+	loginKey, emailKey := identityKeys(u)
+	if loginKey == "" && emailKey == "" {
+		return u
+	}
+
+	matches := map[int]bool{}
+	if idx, ok := i.byLogin[loginKey]; ok && loginKey != "" {
+		matches[idx] = true
+	}
+	if idx, ok := i.byEmail[emailKey]; ok && emailKey != "" {
+		matches[idx] = true
+	}
+
+	if len(matches) == 0 {
+		person := *u
+		i.people = append(i.people, &person)
+		i.index(len(i.people)-1, &person)
+		return &person
+	}
+
+	canonical := -1
+	for idx := range matches {
+		if canonical < 0 || idx < canonical {
+			canonical = idx
+		}
+	}
+	person := i.people[canonical]
+	for idx := range matches {
+		if idx != canonical {
+			mergeGitUsers(i.people[idx], person)
+			i.redirect(idx, canonical)
+		}
+	}
+	mergeGitUsers(u, person)
+	i.index(canonical, person)
+	i.index(canonical, u)
+	return person
+}
+
+// Canonical returns a copy of the canonical record of the person or the details themselves if they are not known
+func (i *Identities) Canonical(u *v1alpha1.UserDetails) *v1alpha1.UserDetails {
+	if u == nil {
+		return nil
+	}
+	loginKey, emailKey := identityKeys(u)
+	idx, ok := i.byLogin[loginKey]
+	if !ok || loginKey == "" {
+		idx, ok = i.byEmail[emailKey]
+		if !ok || emailKey == "" {
+			return u
+		}
+	}
+	person := *i.people[idx]
+	person.Accounts = append([]v1alpha1.AccountReference(nil), person.Accounts...)
+	return &person
+}
+
+// redirect points the keys of a record merged into another one at the canonical record
+func (i *Identities) redirect(from, to int) {
+	for k, v := range i.byLogin {
+		if v == from {
+			i.byLogin[k] = to
+		}
+	}
+	for k, v := range i.byEmail {
+		if v == from {
+			i.byEmail[k] = to
+		}
+	}
+	i.people[from] = nil
+}
+
+func (i *Identities) index(idx int, u *v1alpha1.UserDetails) {
+	loginKey, emailKey := identityKeys(u)
+	if loginKey != "" {
+		i.byLogin[loginKey] = idx
+	}
+	if emailKey != "" {
+		i.byEmail[emailKey] = idx
+	}
+}
+
+func identityKeys(u *v1alpha1.UserDetails) (string, string) {
+	return strings.ToLower(strings.TrimSpace(u.Login)), strings.ToLower(strings.TrimSpace(u.Email))
+}
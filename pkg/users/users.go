@@ -10,6 +10,7 @@ import (
 	"github.com/jenkins-x/jx-helpers/v3/pkg/kube/naming"
 	"github.com/jenkins-x/jx-helpers/v3/pkg/scmhelpers"
 	"github.com/pkg/errors"
+	"github.com/shuttlerock/changlog/pkg/retry"
 
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 )
@@ -17,7 +18,30 @@ import (
 // GitUserResolver allows git users to be converted to Jenkins X users
 type GitUserResolver struct {
 	GitProvider *scm.Client
-	cache       UserDetailService
+
+	// Context the context of the calls to the git provider. Defaults to the background context
+	Context context.Context
+
+	// Retry how the calls to the git provider are timed out and retried
+	Retry retry.Options
+
+	// NoProvider resolves users from their git signature alone without calling the git provider
+	NoProvider bool
+
+	cache UserDetailService
+}
+
+// NewNoProviderResolver creates a resolver which never calls a git provider so that users are only resolved from
+// their git signatures, for repositories which are local or on an unknown git host
+func NewNoProviderResolver() *GitUserResolver {
+	return &GitUserResolver{
+		NoProvider: true,
+	}
+}
+
+// HasProvider returns true if users are looked up on the git provider
+func (r *GitUserResolver) HasProvider() bool {
+	return r != nil && !r.NoProvider && r.GitProvider != nil && r.GitProvider.Users != nil
 }
 
 // GitSignatureAsUser resolves the signature to a Jenkins X User
@@ -52,7 +76,7 @@ func (r *GitUserResolver) GitUserSliceAsUserDetailsSlice(users []scm.User) ([]v1
 // Resolve will convert the GitUser to a Jenkins X user and attempt to complete the user info by:
 // * checking the user custom resources to see if the user is present there
 // * making a call to the gitProvider
-// as often user info is not complete in a git response
+// as often user info is not complete in a git response. Without a git provider the git user is used as is
 func (r *GitUserResolver) Resolve(user *scm.User) (*v1alpha1.UserDetails, error) {
 	if r == nil || user == nil || user.Name == "" {
 		return nil, nil
@@ -63,9 +87,7 @@ func (r *GitUserResolver) Resolve(user *scm.User) (*v1alpha1.UserDetails, error)
 		return u, nil
 	}
 
-	ctx := context.Background()
-
-	if user.Login == "" {
+	if user.Login == "" || !r.HasProvider() {
 		u = r.GitUserToUser(user)
 		err := r.cache.CreateOrUpdateUser(u)
 		if err != nil {
@@ -74,7 +96,20 @@ func (r *GitUserResolver) Resolve(user *scm.User) (*v1alpha1.UserDetails, error)
 		return u, nil
 	}
 
-	scmUser, _, err := r.GitProvider.Users.FindLogin(ctx, user.Login)
+	ctx := r.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var scmUser *scm.User
+	err := retry.Do(ctx, r.Retry, func(ctx context.Context) error {
+		var res *scm.Response
+		var err error
+		scmUser, res, err = r.GitProvider.Users.FindLogin(ctx, user.Login)
+		if scmhelpers.IsScmNotFound(err) {
+			return retry.Permanent(err)
+		}
+		return retry.ScmError(res, err)
+	})
 	if scmUser == nil || scmhelpers.IsScmNotFound(err) {
 		return nil, nil
 	}
@@ -97,35 +132,47 @@ func (r *GitUserResolver) Resolve(user *scm.User) (*v1alpha1.UserDetails, error)
 	return u, nil
 }
 
-/* TODO
-// UpdateUserFromPRAuthor will attempt to use the
-func (r *GitUserResolver) UpdateUserFromPRAuthor(author *jenkinsv1.User, pullRequest *scm.PullRequest,
-	commits []*gits.GitCommit) (*jenkinsv1.User, error) {
-
-	if pullRequest != nil {
-		updated := false
-		if author != nil {
-			gitLogin := r.GitUserLogin(author)
-			if gitLogin == "" {
-				gitLogin = author.Spec.Login
-			}
-			for _, commit := range commits {
-				if commit.Author != nil && gitLogin == commit.Author.Login {
-					log.Logger().Info("Found commit author match for: " + author.
-						Spec.Login + " with email address: " + commit.Author.Email + "\n")
-					author.Spec.Email = commit.Author.Email
-					updated = true
-					break
-				}
-			}
+// UpdateUserFromPRAuthor takes over the email of the pull request author from the commits they authored as the
+// git provider often does not expose it, matching the commits by login
+func (r *GitUserResolver) UpdateUserFromPRAuthor(author *v1alpha1.UserDetails, pullRequest *scm.PullRequest,
+	commits []*scm.Commit) (*v1alpha1.UserDetails, error) {
+
+	if pullRequest == nil || author == nil {
+		return author, nil
+	}
+	login := author.Login
+	if login == "" {
+		login = pullRequest.Author.Login
+	}
+	if login == "" {
+		return author, nil
+	}
+	updated := false
+	for _, commit := range commits {
+		if commit == nil || commit.Author.Email == "" || !strings.EqualFold(commit.Author.Login, login) {
+			continue
 		}
-		if updated {
-			return r.JXClient.JenkinsV1().Users(r.Namespace).PatchUpdate(author)
+		if author.Email != commit.Author.Email {
+			author.Email = commit.Author.Email
+			updated = true
+		}
+		if (author.Name == "" || author.Name == author.Login) && commit.Author.Name != "" && author.Name != commit.Author.Name {
+			author.Name = commit.Author.Name
+			updated = true
+		}
+		break
+	}
+	if updated {
+		if author.Login == "" {
+			author.Login = login
+		}
+		err := r.cache.CreateOrUpdateUser(author)
+		if err != nil {
+			return author, errors.Wrapf(err, "failed to update User %s", login)
 		}
 	}
 	return author, nil
 }
-*/
 
 // GitUserToUser performs type conversion from a GitUser to a Jenkins X user,
 // attaching the Git Provider account to Accounts
@@ -146,3 +193,46 @@ func (r *GitUserResolver) GitProviderKey() string {
 }
 
 // mergeGitUsers merges user1 into user2, replacing any that do not have empty values on user2 with those from user1
+func mergeGitUsers(user1, user2 *v1alpha1.UserDetails) {
+	if user1 == nil || user2 == nil {
+		return
+	}
+	if user2.Login == "" {
+		user2.Login = user1.Login
+	}
+	if user2.Name == "" || user2.Name == user2.Login {
+		if user1.Name != "" && user1.Name != user1.Login {
+			user2.Name = user1.Name
+		} else if user2.Name == "" {
+			user2.Name = user1.Name
+		}
+	}
+	if user2.Email == "" {
+		user2.Email = user1.Email
+	}
+	if user2.URL == "" {
+		user2.URL = user1.URL
+	}
+	if user2.AvatarURL == "" {
+		user2.AvatarURL = user1.AvatarURL
+	}
+	if user2.ServiceAccount == "" {
+		user2.ServiceAccount = user1.ServiceAccount
+	}
+	if user2.CreationTimestamp == nil {
+		user2.CreationTimestamp = user1.CreationTimestamp
+	}
+	user2.ExternalUser = user2.ExternalUser || user1.ExternalUser
+	for _, account := range user1.Accounts {
+		found := false
+		for _, existing := range user2.Accounts {
+			if existing == account {
+				found = true
+				break
+			}
+		}
+		if !found {
+			user2.Accounts = append(user2.Accounts, account)
+		}
+	}
+}